@@ -0,0 +1,165 @@
+package otelgqlgen
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type listItemAggregatesKey struct{}
+
+// listItemAggregates collects the resolutions of the fields under the list items during an operation.
+type listItemAggregates struct {
+	byPath map[string]*listItemAggregate
+	paths  []string
+	mu     sync.Mutex
+}
+
+type listItemAggregate struct {
+	startedAt time.Time
+	endedAt   time.Time
+	fieldCtx  *graphql.FieldContext
+	durations []time.Duration
+	errors    int
+	parent    trace.SpanContext
+}
+
+func withListItemAggregates(ctx context.Context) (context.Context, *listItemAggregates) {
+	aggs := &listItemAggregates{byPath: map[string]*listItemAggregate{}}
+	return context.WithValue(ctx, listItemAggregatesKey{}, aggs), aggs
+}
+
+func listItemAggregatesFromContext(ctx context.Context) *listItemAggregates {
+	aggs, _ := ctx.Value(listItemAggregatesKey{}).(*listItemAggregates)
+	return aggs
+}
+
+// isListItemField returns whether the field is resolved under any list item.
+func isListItemField(fc *graphql.FieldContext) bool {
+	for p := fc.Parent; p != nil; p = p.Parent {
+		if p.Index != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// aggregatedPath returns the path of the field without the list indexes (e.g. "users.name" for "users[0].name").
+func aggregatedPath(fc *graphql.FieldContext) string {
+	path := fc.Path()
+	names := make([]string, 0, len(path))
+	for _, elem := range path {
+		if name, ok := elem.(ast.PathName); ok {
+			names = append(names, string(name))
+		}
+	}
+	return strings.Join(names, ".")
+}
+
+// withoutListIndexes returns the copy of the field context and its ancestors without the list indexes.
+//
+// It represents the fields that share the aggregated path, so that the field span name formatter names the aggregated span like StableFieldSpanNames.
+func withoutListIndexes(fc *graphql.FieldContext) *graphql.FieldContext {
+	if fc == nil {
+		return nil
+	}
+	ret := *fc
+	ret.Index = nil
+	ret.Parent = withoutListIndexes(fc.Parent)
+	return &ret
+}
+
+func (aggs *listItemAggregates) add(parent trace.SpanContext, fc *graphql.FieldContext, startedAt, endedAt time.Time, errors int) {
+	path := aggregatedPath(fc)
+	aggs.mu.Lock()
+	defer aggs.mu.Unlock()
+	agg, ok := aggs.byPath[path]
+	if !ok {
+		agg = &listItemAggregate{
+			fieldCtx:  withoutListIndexes(fc),
+			startedAt: startedAt,
+			parent:    parent,
+		}
+		aggs.byPath[path] = agg
+		aggs.paths = append(aggs.paths, path)
+	}
+	if startedAt.Before(agg.startedAt) {
+		agg.startedAt = startedAt
+	}
+	if endedAt.After(agg.endedAt) {
+		agg.endedAt = endedAt
+	}
+	agg.durations = append(agg.durations, endedAt.Sub(startedAt))
+	agg.errors += errors
+}
+
+func (t Tracer) isSampledListItem(fc *graphql.FieldContext) bool {
+	idx := fieldIndex(fc)
+	return idx != nil && *idx < t.aggregateSampleItems
+}
+
+func (t Tracer) interceptAggregatedField(ctx context.Context, aggs *listItemAggregates, next graphql.Resolver) (any, error) {
+	fieldCtx := graphql.GetFieldContext(ctx)
+	defer t.recordResolverMetrics(ctx, fieldCtx, time.Now())
+	return aggs.wrap(trace.SpanContextFromContext(ctx), fieldCtx, next)(ctx)
+}
+
+// wrap returns the resolver that adds the resolution of next to the aggregate.
+//
+// The error returned by next is counted in addition to the field errors because gqlgen adds it to the response after the field interceptors.
+func (aggs *listItemAggregates) wrap(parent trace.SpanContext, fc *graphql.FieldContext, next graphql.Resolver) graphql.Resolver {
+	return func(ctx context.Context) (any, error) {
+		startedAt := time.Now()
+		resp, err := next(ctx)
+		errors := len(graphql.GetFieldErrors(ctx, fc))
+		if err != nil {
+			errors++
+		}
+		aggs.add(parent, fc, startedAt, time.Now(), errors)
+		return resp, err
+	}
+}
+
+// end creates the aggregated spans retroactively.
+func (aggs *listItemAggregates) end(ctx context.Context, t Tracer) {
+	aggs.mu.Lock()
+	defer aggs.mu.Unlock()
+	for _, path := range aggs.paths {
+		agg := aggs.byPath[path]
+		parentCtx := trace.ContextWithSpanContext(ctx, agg.parent)
+		_, span := t.tracer.Start(parentCtx, t.fieldSpanName(agg.fieldCtx),
+			trace.WithTimestamp(agg.startedAt),
+			trace.WithSpanKind(t.nestedSpanKind),
+			trace.WithAttributes(agg.attributes(path)...))
+		span.End(trace.WithTimestamp(agg.endedAt))
+	}
+}
+
+func (agg *listItemAggregate) attributes(path string) []attribute.KeyValue {
+	durations := slices.Clone(agg.durations)
+	slices.Sort(durations)
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	p99 := durations[int(math.Ceil(float64(len(durations))*0.99))-1]
+	return []attribute.KeyValue{
+		keyResolverObject.String(agg.fieldCtx.Field.ObjectDefinition.Name),
+		keyResolverFieldName.String(agg.fieldCtx.Field.Name),
+		keyResolverPath.String(path),
+		keyAggregateCount.Int(len(durations)),
+		keyAggregateErrors.Int(agg.errors),
+		keyAggregateDurationTotal.Float64(total.Seconds()),
+		keyAggregateDurationMin.Float64(durations[0].Seconds()),
+		keyAggregateDurationMax.Float64(durations[len(durations)-1].Seconds()),
+		keyAggregateDurationP99.Float64(p99.Seconds()),
+	}
+}
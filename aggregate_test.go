@@ -0,0 +1,103 @@
+package otelgqlgen_test
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer_aggregateListItemFields(t *testing.T) {
+	type testCase struct {
+		name        string
+		sampleItems int
+		names       []string
+		want        tracetest.SpanStubs
+	}
+	testCases := []testCase{
+		{
+			name:  "ok",
+			names: []string{"a", "b", "c"},
+			want: tracetest.SpanStubs{
+				{Name: "Query/users", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users")}},
+				{Name: "User/name", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users.name"), attribute.Int("graphql.resolver.aggregate.count", 3), attribute.Int("graphql.resolver.aggregate.errors", 0)}},
+			},
+		},
+		{
+			name:        "sampled",
+			sampleItems: 1,
+			names:       []string{"a", "b", "c"},
+			want: tracetest.SpanStubs{
+				{Name: "Query/users", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users")}},
+				{Name: "User/0/name", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users[0].name")}},
+				{Name: "User/name", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users.name"), attribute.Int("graphql.resolver.aggregate.count", 3), attribute.Int("graphql.resolver.aggregate.errors", 0)}},
+			},
+		},
+		{
+			name:  "errors",
+			names: []string{"a", "invalid"},
+			want: tracetest.SpanStubs{
+				{Name: "Query/users", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users")}},
+				{Name: "User/name", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users.name"), attribute.Int("graphql.resolver.aggregate.count", 2), attribute.Int("graphql.resolver.aggregate.errors", 1)}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, otelgqlgen.ShouldTraceCaptureTimings(false), otelgqlgen.AggregateListItemFields(tc.sampleItems))
+			_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{
+				Query:     `query($names: [String!]!) {users(names: $names) {name}}`,
+				Variables: map[string]any{"names": tc.names},
+			})
+			got := make(tracetest.SpanStubs, 0)
+			for _, span := range exporter.GetSpans() {
+				attrs := make([]attribute.KeyValue, 0, 3)
+				for _, attr := range span.Attributes {
+					switch attr.Key {
+					case "graphql.resolver.path", "graphql.resolver.aggregate.count", "graphql.resolver.aggregate.errors":
+						attrs = append(attrs, attr)
+					}
+				}
+				if len(attrs) == 0 {
+					continue
+				}
+				got = append(got, tracetest.SpanStub{Name: span.Name, Attributes: attrs})
+			}
+			if diff := cmpFieldSpans(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+			aggregated := findSpan(t, exporter.GetSpans(), "User/name")
+			parent := findSpan(t, exporter.GetSpans(), "Query/users")
+			if aggregated.Parent.SpanID() != parent.SpanContext.SpanID() {
+				t.Errorf("parent: want=%s got=%s", parent.SpanContext.SpanID(), aggregated.Parent.SpanID())
+			}
+		})
+	}
+}
+
+func TestTracer_aggregateListItemFields_spanNameFormatter(t *testing.T) {
+	ctx := testContext(t)
+	gqlsrv, exporter := newTestServer(t,
+		otelgqlgen.ShouldTraceCaptureTimings(false),
+		otelgqlgen.AggregateListItemFields(1),
+		otelgqlgen.WithSpanNameFormatter(otelgqlgen.SpanNameFormatter{
+			Field: func(fc *graphql.FieldContext) string { return "field " + fc.Path().String() },
+		}))
+	_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{
+		Query:     `query($names: [String!]!) {users(names: $names) {name}}`,
+		Variables: map[string]any{"names": []string{"a", "b", "c"}},
+	})
+	spans := exporter.GetSpans()
+	sampled := findSpan(t, spans, "field users[0].name")
+	aggregated := findSpan(t, spans, "field users.name")
+	parent := findSpan(t, spans, "field users")
+	if aggregated.Parent.SpanID() != parent.SpanContext.SpanID() {
+		t.Errorf("parent: want=%s got=%s", parent.SpanContext.SpanID(), aggregated.Parent.SpanID())
+	}
+	if sampled.Parent.SpanID() != parent.SpanContext.SpanID() {
+		t.Errorf("sampled parent: want=%s got=%s", parent.SpanContext.SpanID(), sampled.Parent.SpanID())
+	}
+}
@@ -0,0 +1,86 @@
+package otelgqlgen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/apollotracing"
+)
+
+const apolloTracingExtensionKey = "tracing"
+
+type apolloTracingKey struct{}
+
+// apolloTracing collects the resolver executions of an operation in the Apollo Tracing v1 format.
+type apolloTracing struct {
+	ext *apollotracing.TracingExtension
+	mu  sync.Mutex
+}
+
+// withApolloTracing registers the Apollo Tracing extension to the response if the predicate allows it.
+func (t Tracer) withApolloTracing(ctx context.Context) (context.Context, *apolloTracing) {
+	if t.apolloTracing == nil || !t.apolloTracing(ctx) {
+		return ctx, nil
+	}
+	stats := graphql.GetOperationContext(ctx).Stats
+	start := stats.OperationStart
+	if start.IsZero() {
+		start = time.Now()
+	}
+	at := &apolloTracing{
+		ext: &apollotracing.TracingExtension{
+			Version:   1,
+			StartTime: start,
+			Parsing: apollotracing.Span{
+				StartOffset: stats.Parsing.Start.Sub(start),
+				Duration:    stats.Parsing.End.Sub(stats.Parsing.Start),
+			},
+			Validation: apollotracing.Span{
+				StartOffset: stats.Validation.Start.Sub(start),
+				Duration:    stats.Validation.End.Sub(stats.Validation.Start),
+			},
+		},
+	}
+	at.ext.Execution.Resolvers = []*apollotracing.ResolverExecution{}
+	graphql.RegisterExtension(ctx, apolloTracingExtensionKey, at.ext)
+	return context.WithValue(ctx, apolloTracingKey{}, at), at
+}
+
+func apolloTracingFromContext(ctx context.Context) *apolloTracing {
+	at, _ := ctx.Value(apolloTracingKey{}).(*apolloTracing)
+	return at
+}
+
+// wrap returns the resolver that records the execution of next.
+func (at *apolloTracing) wrap(fc *graphql.FieldContext, next graphql.Resolver) graphql.Resolver {
+	return func(ctx context.Context) (any, error) {
+		startedAt := time.Now()
+		defer func() {
+			endedAt := time.Now()
+			resolver := &apollotracing.ResolverExecution{
+				Path:        fc.Path(),
+				ParentType:  fc.Object,
+				FieldName:   fc.Field.Name,
+				ReturnType:  fc.Field.Definition.Type.String(),
+				StartOffset: startedAt.Sub(at.ext.StartTime),
+				Duration:    endedAt.Sub(startedAt),
+			}
+			at.mu.Lock()
+			defer at.mu.Unlock()
+			at.ext.Execution.Resolvers = append(at.ext.Execution.Resolvers, resolver)
+		}()
+		return next(ctx)
+	}
+}
+
+func (at *apolloTracing) end() {
+	if at == nil {
+		return
+	}
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	at.ext.EndTime = time.Now()
+	at.ext.Duration = at.ext.EndTime.Sub(at.ext.StartTime)
+}
@@ -0,0 +1,75 @@
+package otelgqlgen_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type apolloTracingResolver struct {
+	Path       []any  `json:"path"`
+	ParentType string `json:"parentType"`
+	FieldName  string `json:"fieldName"`
+	ReturnType string `json:"returnType"`
+}
+
+type apolloTracing struct {
+	Version   int `json:"version"`
+	Execution struct {
+		Resolvers []apolloTracingResolver `json:"resolvers"`
+	} `json:"execution"`
+}
+
+func TestTracer_apolloTracing(t *testing.T) {
+	type testCase struct {
+		name   string
+		header http.Header
+		want   *apolloTracing
+	}
+	testCases := []testCase{
+		{
+			name:   "enabled",
+			header: http.Header{"X-Apollo-Tracing": []string{"1"}},
+			want: func() *apolloTracing {
+				at := &apolloTracing{Version: 1}
+				at.Execution.Resolvers = []apolloTracingResolver{
+					{Path: []any{"user"}, ParentType: "Query", FieldName: "user", ReturnType: "User"},
+					{Path: []any{"user", "name"}, ParentType: "User", FieldName: "name", ReturnType: "String!"},
+					{Path: []any{"user", "isAdmin"}, ParentType: "User", FieldName: "isAdmin", ReturnType: "Boolean!"},
+				}
+				return at
+			}(),
+		},
+		{
+			name: "disabled",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, _ := newTestServer(t,
+				otelgqlgen.WithApolloTracing(func(ctx context.Context) bool {
+					return graphql.GetOperationContext(ctx).Headers.Get("X-Apollo-Tracing") != ""
+				}))
+			body := doRequestWithHeader(ctx, t, gqlsrv, &graphql.RawParams{Query: `{user(name: "aereal") {name isAdmin}}`}, tc.header)
+			var resp struct {
+				Extensions struct {
+					Tracing *apolloTracing `json:"tracing"`
+				} `json:"extensions"`
+			}
+			if err := json.Unmarshal(body, &resp); err != nil {
+				t.Fatal(err)
+			}
+			opts := []cmp.Option{cmpopts.SortSlices(func(x, y apolloTracingResolver) bool { return x.FieldName < y.FieldName })}
+			if diff := cmp.Diff(tc.want, resp.Extensions.Tracing, opts...); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
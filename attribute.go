@@ -0,0 +1,188 @@
+package otelgqlgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// flattenAttrs returns the attributes of the given Go value.
+//
+// The maps are flattened into the attributes per the keys.
+// If typed is true, the attributes keep the type of the values and the null values are omitted. Otherwise, the values are formatted as the strings.
+func flattenAttrs(ns attrNameHierarchy, val any, typed bool) []attribute.KeyValue {
+	if typed && val == nil {
+		return nil
+	}
+	m, ok := val.(map[string]any)
+	if !ok {
+		if !typed {
+			return []attribute.KeyValue{ns.asKey().String(fmt.Sprintf("%+v", val))}
+		}
+		return []attribute.KeyValue{typedAttr(ns.asKey(), val)}
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	attrs := make([]attribute.KeyValue, 0, len(m))
+	for _, k := range keys {
+		attrs = append(attrs, flattenAttrs(ns.With(k), m[k], typed)...)
+	}
+	return attrs
+}
+
+// normalizeValue converts the value into the JSON-compatible one (e.g. the struct into the map) so that it can be flattened.
+func normalizeValue(val any) any {
+	switch val.(type) {
+	case nil, string, bool, int, int32, int64, float32, float64, json.Number, map[string]any, []any:
+		return val
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		return val
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var normalized any
+	if err := dec.Decode(&normalized); err != nil {
+		return val
+	}
+	return normalized
+}
+
+func typedAttr(key attribute.Key, val any) attribute.KeyValue {
+	switch val := val.(type) {
+	case string:
+		return key.String(val)
+	case bool:
+		return key.Bool(val)
+	case int:
+		return key.Int(val)
+	case int32:
+		return key.Int64(int64(val))
+	case int64:
+		return key.Int64(val)
+	case float32:
+		return key.Float64(float64(val))
+	case float64:
+		return key.Float64(val)
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return key.Int64(i)
+		}
+		if f, err := val.Float64(); err == nil {
+			return key.Float64(f)
+		}
+		return key.String(val.String())
+	case []any:
+		if kv, ok := typedSliceAttr(key, val); ok {
+			return kv
+		}
+	}
+	return key.String(fmt.Sprintf("%+v", val))
+}
+
+func typedSliceAttr(key attribute.Key, vals []any) (attribute.KeyValue, bool) {
+	if len(vals) == 0 {
+		return attribute.KeyValue{}, false
+	}
+	elems := make([]attribute.KeyValue, len(vals))
+	for i, v := range vals {
+		elems[i] = typedAttr(key, v)
+	}
+	return sliceAttr(key, elems)
+}
+
+// sliceAttr returns the slice attribute built from the scalar attributes of the same type.
+//
+// The integers are converted into the floats if both of them are mixed.
+func sliceAttr(key attribute.Key, elems []attribute.KeyValue) (attribute.KeyValue, bool) {
+	typ := elems[0].Value.Type()
+	for _, elem := range elems[1:] {
+		switch t := elem.Value.Type(); {
+		case t == typ:
+		case t == attribute.FLOAT64 && typ == attribute.INT64, t == attribute.INT64 && typ == attribute.FLOAT64:
+			typ = attribute.FLOAT64
+		default:
+			return attribute.KeyValue{}, false
+		}
+	}
+	if typ == attribute.STRING {
+		xs := make([]string, len(elems))
+		for i, elem := range elems {
+			xs[i] = elem.Value.AsString()
+		}
+		return key.StringSlice(xs), true
+	}
+	if typ == attribute.BOOL {
+		xs := make([]bool, len(elems))
+		for i, elem := range elems {
+			xs[i] = elem.Value.AsBool()
+		}
+		return key.BoolSlice(xs), true
+	}
+	if typ == attribute.INT64 {
+		xs := make([]int64, len(elems))
+		for i, elem := range elems {
+			xs[i] = elem.Value.AsInt64()
+		}
+		return key.Int64Slice(xs), true
+	}
+	if typ == attribute.FLOAT64 {
+		xs := make([]float64, len(elems))
+		for i, elem := range elems {
+			if elem.Value.Type() == attribute.INT64 {
+				xs[i] = float64(elem.Value.AsInt64())
+				continue
+			}
+			xs[i] = elem.Value.AsFloat64()
+		}
+		return key.Float64Slice(xs), true
+	}
+	return attribute.KeyValue{}, false
+}
+
+// typedASTAttr returns the attribute that keeps the type of the given literal value.
+//
+// It returns false if the value is a variable, null, an object or a list of the mixed types.
+func typedASTAttr(key attribute.Key, val *ast.Value) (attribute.KeyValue, bool) {
+	if val == nil {
+		return attribute.KeyValue{}, false
+	}
+	switch val.Kind {
+	case ast.IntValue:
+		if i, err := strconv.ParseInt(val.Raw, 10, 64); err == nil {
+			return key.Int64(i), true
+		}
+	case ast.FloatValue:
+		if f, err := strconv.ParseFloat(val.Raw, 64); err == nil {
+			return key.Float64(f), true
+		}
+	case ast.BooleanValue:
+		return key.Bool(val.Raw == "true"), true
+	case ast.StringValue, ast.BlockValue, ast.EnumValue:
+		return key.String(val.Raw), true
+	case ast.ListValue:
+		if len(val.Children) == 0 {
+			return attribute.KeyValue{}, false
+		}
+		elems := make([]attribute.KeyValue, len(val.Children))
+		for i, child := range val.Children {
+			elem, ok := typedASTAttr(key, child.Value)
+			if !ok {
+				return attribute.KeyValue{}, false
+			}
+			elems[i] = elem
+		}
+		return sliceAttr(key, elems)
+	case ast.Variable, ast.NullValue, ast.ObjectValue:
+	}
+	return attribute.KeyValue{}, false
+}
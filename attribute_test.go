@@ -0,0 +1,110 @@
+package otelgqlgen_test
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestTracer_typedAttributes(t *testing.T) {
+	type testCase struct {
+		name      string
+		params    *graphql.RawParams
+		fieldSpan string
+		wantArgs  []attribute.KeyValue
+		wantVars  []attribute.KeyValue
+	}
+	testCases := []testCase{
+		{
+			name:      "literal scalars",
+			params:    &graphql.RawParams{Query: `{search(limit: 100, ratio: 0.5, exact: true, tags: ["a", "b"])}`},
+			fieldSpan: "Query/search",
+			wantArgs: []attribute.KeyValue{
+				attribute.Int64("graphql.resolver.args.limit", 100),
+				attribute.Float64("graphql.resolver.args.ratio", 0.5),
+				attribute.Bool("graphql.resolver.args.exact", true),
+				attribute.StringSlice("graphql.resolver.args.tags", []string{"a", "b"}),
+			},
+			wantVars: []attribute.KeyValue{},
+		},
+		{
+			name: "variables",
+			params: &graphql.RawParams{
+				Query:     `query($limit: Int!, $ratio: Float, $exact: Boolean, $tags: [String!]) {search(limit: $limit, ratio: $ratio, exact: $exact, tags: $tags)}`,
+				Variables: map[string]any{"limit": 100, "ratio": 0.5, "exact": true, "tags": []string{"a", "b"}},
+			},
+			fieldSpan: "Query/search",
+			wantArgs: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.limit", "$limit"),
+				attribute.String("graphql.resolver.args.ratio", "$ratio"),
+				attribute.String("graphql.resolver.args.exact", "$exact"),
+				attribute.String("graphql.resolver.args.tags", "$tags"),
+			},
+			wantVars: []attribute.KeyValue{
+				attribute.Int64("graphql.operation.variables.limit", 100),
+				attribute.Float64("graphql.operation.variables.ratio", 0.5),
+				attribute.Bool("graphql.operation.variables.exact", true),
+				attribute.StringSlice("graphql.operation.variables.tags", []string{"a", "b"}),
+			},
+		},
+		{
+			name: "nulls",
+			params: &graphql.RawParams{
+				Query:     `query($exact: Boolean) {search(limit: 1, ratio: null, exact: $exact)}`,
+				Variables: map[string]any{"exact": nil},
+			},
+			fieldSpan: "Query/search",
+			wantArgs: []attribute.KeyValue{
+				attribute.Int64("graphql.resolver.args.limit", 1),
+				attribute.String("graphql.resolver.args.exact", "$exact"),
+				attribute.Bool("graphql.resolver.args.tags.default", true),
+			},
+			wantVars: []attribute.KeyValue{},
+		},
+		{
+			name:      "literal object",
+			params:    &graphql.RawParams{Query: `{root(num: 1, rootInput: {nested: {val: "x"}})}`},
+			fieldSpan: "Query/root",
+			wantArgs: []attribute.KeyValue{
+				attribute.Int64("graphql.resolver.args.num", 1),
+				attribute.String("graphql.resolver.args.rootInput.nested.val", "x"),
+			},
+			wantVars: []attribute.KeyValue{},
+		},
+		{
+			name: "object variable",
+			params: &graphql.RawParams{
+				Query:     `query($rootInput: RootInput) {root(num: 1, rootInput: $rootInput)}`,
+				Variables: map[string]any{"rootInput": map[string]any{"nested": map[string]any{"val": "x"}}},
+			},
+			fieldSpan: "Query/root",
+			wantArgs: []attribute.KeyValue{
+				attribute.Int64("graphql.resolver.args.num", 1),
+				attribute.String("graphql.resolver.args.rootInput", "$rootInput"),
+			},
+			wantVars: []attribute.KeyValue{
+				attribute.String("graphql.operation.variables.rootInput.nested.val", "x"),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, otelgqlgen.TypedAttributes(true))
+			_ = doRequest(ctx, t, gqlsrv, tc.params)
+			spans := exporter.GetSpans()
+			opts := []cmp.Option{cmp.Transformer("attribute.KeyValue", transformKeyValue), sortAttrs}
+			gotArgs := attrsWithPrefix(findSpan(t, spans, tc.fieldSpan).Attributes, "graphql.resolver.args.")
+			if diff := cmp.Diff(tc.wantArgs, gotArgs, opts...); diff != "" {
+				t.Errorf("args: -want, +got:\n%s", diff)
+			}
+			gotVars := attrsWithPrefix(findSpan(t, spans, "query").Attributes, "graphql.operation.variables.")
+			if diff := cmp.Diff(tc.wantVars, gotVars, opts...); diff != "" {
+				t.Errorf("variables: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
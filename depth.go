@@ -0,0 +1,37 @@
+package otelgqlgen
+
+import (
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// fieldDepth returns the depth of the field that the list indexes are not counted (e.g. 2 for "users[0].name").
+func fieldDepth(fc *graphql.FieldContext) int {
+	depth := 0
+	for it := fc; it != nil; it = it.Parent {
+		if it.Index == nil && it.Field.Field != nil {
+			depth++
+		}
+	}
+	return depth
+}
+
+// countSelectedFields returns the number of the fields selected under the selection set.
+//
+// The fields are counted per the selection, so the fields under a list are counted once regardless of the number of the items.
+func countSelectedFields(set ast.SelectionSet) int {
+	n := 0
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			n += 1 + countSelectedFields(sel.SelectionSet)
+		case *ast.InlineFragment:
+			n += countSelectedFields(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			if sel.Definition != nil {
+				n += countSelectedFields(sel.Definition.SelectionSet)
+			}
+		}
+	}
+	return n
+}
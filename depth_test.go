@@ -0,0 +1,87 @@
+package otelgqlgen_test
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer_maxFieldDepth(t *testing.T) {
+	type testCase struct {
+		name     string
+		maxDepth int
+		query    string
+		want     tracetest.SpanStubs
+	}
+	testCases := []testCase{
+		{
+			name:  "no limit",
+			query: `{user(name: "aereal") {name age}}`,
+			want: tracetest.SpanStubs{
+				{Name: "Query/user", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "user")}},
+				{Name: "User/name", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "user.name")}},
+				{Name: "User/age", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "user.age")}},
+			},
+		},
+		{
+			name:     "limited",
+			maxDepth: 1,
+			query:    `{user(name: "aereal") {name age}}`,
+			want: tracetest.SpanStubs{
+				{Name: "Query/user", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "user"), attribute.Int("graphql.resolver.untraced_fields", 2)}},
+			},
+		},
+		{
+			name:     "limited with fragments",
+			maxDepth: 1,
+			query:    `query {user(name: "aereal") {...userFields ... on User {isAdmin}}} fragment userFields on User {name age}`,
+			want: tracetest.SpanStubs{
+				{Name: "Query/user", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "user"), attribute.Int("graphql.resolver.untraced_fields", 3)}},
+			},
+		},
+		{
+			name:     "list",
+			maxDepth: 1,
+			query:    `{users(names: ["a", "b"]) {name}}`,
+			want: tracetest.SpanStubs{
+				{Name: "Query/users", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users"), attribute.Int("graphql.resolver.untraced_fields", 1)}},
+			},
+		},
+		{
+			name:     "limit deeper than query",
+			maxDepth: 2,
+			query:    `{user(name: "aereal") {name age}}`,
+			want: tracetest.SpanStubs{
+				{Name: "Query/user", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "user")}},
+				{Name: "User/name", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "user.name")}},
+				{Name: "User/age", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "user.age")}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, otelgqlgen.ShouldTraceCaptureTimings(false), otelgqlgen.WithMaxFieldDepth(tc.maxDepth))
+			_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{Query: tc.query})
+			got := make(tracetest.SpanStubs, 0)
+			for _, span := range exporter.GetSpans() {
+				attrs := make([]attribute.KeyValue, 0, 2)
+				for _, attr := range span.Attributes {
+					if attr.Key == "graphql.resolver.path" || attr.Key == "graphql.resolver.untraced_fields" {
+						attrs = append(attrs, attr)
+					}
+				}
+				if len(attrs) == 0 {
+					continue
+				}
+				got = append(got, tracetest.SpanStub{Name: span.Name, Attributes: attrs})
+			}
+			if diff := cmpFieldSpans(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
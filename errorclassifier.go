@@ -0,0 +1,94 @@
+package otelgqlgen
+
+import (
+	"slices"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ErrorKind is the classification of the error by whom it is caused.
+type ErrorKind int
+
+const (
+	// ErrorKindUnknown means the error cannot be classified.
+	ErrorKindUnknown ErrorKind = iota
+	// ErrorKindClient means the error is caused by the client (e.g. invalid input, not found), like the HTTP 4xx status.
+	ErrorKindClient
+	// ErrorKindServer means the error is caused by the server (e.g. unavailable dependency), like the HTTP 5xx status.
+	ErrorKindServer
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindClient:
+		return "client"
+	case ErrorKindServer:
+		return "server"
+	case ErrorKindUnknown:
+	}
+	return "unknown"
+}
+
+// ErrorClassifier classifies the error into ErrorKind.
+//
+// The function can classify the error by its type using errors.As or errors.Is.
+type ErrorClassifier func(err error) ErrorKind
+
+// ClassifyByErrorCode returns ErrorClassifier that classifies the error by the code in the error extensions.
+//
+// The errors that have no code or the codes not in the given map are classified as ErrorKindUnknown.
+func ClassifyByErrorCode(kinds map[string]ErrorKind) ErrorClassifier {
+	return func(err error) ErrorKind {
+		gqlErr, ok := err.(*gqlerror.Error)
+		if !ok {
+			return ErrorKindUnknown
+		}
+		return kinds[errorCode(gqlErr)]
+	}
+}
+
+// WithErrorClassifier creates an Option that tells Tracer to classify the recorded errors with the given classifier.
+//
+// The recorded errors have "graphql.error.kind" attribute.
+// Only the server faults set the span status to Error.
+// The client faults are recorded as the plain events, and the unknown errors are recorded as the error recorder decides but leave the span status Unset.
+// See also ClassifyUnknownErrorsAsServer.
+//
+// default value: nil; the errors are not classified
+func WithErrorClassifier(fn ErrorClassifier) Option {
+	return func(c *config) { c.errorClassifier = fn }
+}
+
+// ClassifyUnknownErrorsAsServer creates an Option that tells Tracer to treat the errors classified as ErrorKindUnknown as the server faults.
+//
+// default value: false
+// The true means the unknown errors set the span status to Error and have "server" as "graphql.error.kind" attribute.
+// It has no effect without WithErrorClassifier.
+func ClassifyUnknownErrorsAsServer(v bool) Option {
+	return func(c *config) { c.unknownErrorsAsServer = v }
+}
+
+// decideError returns the decision of the error recorder adjusted by the error classifier.
+func (t Tracer) decideError(err error) ErrorDecision {
+	decision := t.errorRecorder.DecideError(err)
+	if t.errorClassifier == nil || decision.Recording == ErrorIgnore {
+		return decision
+	}
+	kind := t.errorClassifier(err)
+	if kind == ErrorKindUnknown && t.unknownErrorsAsServer {
+		kind = ErrorKindServer
+	}
+	decision.Attributes = append(slices.Clip(decision.Attributes), keyErrorKind.String(kind.String()))
+	switch kind {
+	case ErrorKindClient:
+		decision.Recording = ErrorRecordAsEvent
+		decision.Status = codes.Unset
+	case ErrorKindUnknown:
+		if decision.Status == codes.Error {
+			decision.Status = codes.Unset
+		}
+	case ErrorKindServer:
+	}
+	return decision
+}
@@ -0,0 +1,131 @@
+package otelgqlgen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"github.com/aereal/otelgqlgen/internal/test/resolvers"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestTracer_errorClassifier(t *testing.T) {
+	byCode := otelgqlgen.ClassifyByErrorCode(map[string]otelgqlgen.ErrorKind{
+		"NOT_FOUND": otelgqlgen.ErrorKindClient,
+		"FORBIDDEN": otelgqlgen.ErrorKindServer,
+	})
+	type testCase struct {
+		name            string
+		classifier      otelgqlgen.ErrorClassifier
+		unknownAsServer bool
+		params          *graphql.RawParams
+		wantStatus      sdktrace.Status
+		wantEvents      []sdktrace.Event
+	}
+	testCases := []testCase{
+		{
+			name:       "client fault",
+			classifier: byCode,
+			params:     &graphql.RawParams{Query: `{user(name: "not_found") {name}}`},
+			wantEvents: []sdktrace.Event{
+				{
+					Name: "graphql.error",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "user"),
+						attribute.String("graphql.errors.code", "NOT_FOUND"),
+						attribute.String("graphql.errors.message", "not found"),
+						attribute.String("graphql.error.kind", "client"),
+					},
+				},
+			},
+		},
+		{
+			name:       "server fault",
+			classifier: byCode,
+			params:     &graphql.RawParams{Query: `{user(name: "forbidden") {name}}`},
+			wantStatus: sdktrace.Status{Code: codes.Error, Description: "input: user forbidden\n"},
+			wantEvents: []sdktrace.Event{
+				{
+					Name: "exception",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "user"),
+						attribute.String("graphql.errors.code", "FORBIDDEN"),
+						attribute.String("graphql.error.kind", "server"),
+						attribute.String("exception.type", "github.com/aereal/otelgqlgen/internal/test/resolvers.ForbiddenError"),
+						attribute.String("exception.message", "forbidden"),
+						attrStacktrace,
+					},
+				},
+			},
+		},
+		{
+			name: "unknown",
+			classifier: func(err error) otelgqlgen.ErrorKind {
+				if errors.Is(err, &resolvers.NotFoundError{}) {
+					return otelgqlgen.ErrorKindClient
+				}
+				return otelgqlgen.ErrorKindUnknown
+			},
+			params: &graphql.RawParams{Query: `{user(name: "forbidden") {name}}`},
+			wantEvents: []sdktrace.Event{
+				{
+					Name: "exception",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "user"),
+						attribute.String("graphql.errors.code", "FORBIDDEN"),
+						attribute.String("graphql.error.kind", "unknown"),
+						attribute.String("exception.type", "github.com/aereal/otelgqlgen/internal/test/resolvers.ForbiddenError"),
+						attribute.String("exception.message", "forbidden"),
+						attrStacktrace,
+					},
+				},
+			},
+		},
+		{
+			name: "unknown as server",
+			classifier: func(err error) otelgqlgen.ErrorKind {
+				if errors.Is(err, &resolvers.NotFoundError{}) {
+					return otelgqlgen.ErrorKindClient
+				}
+				return otelgqlgen.ErrorKindUnknown
+			},
+			unknownAsServer: true,
+			params:          &graphql.RawParams{Query: `{user(name: "forbidden") {name}}`},
+			wantStatus:      sdktrace.Status{Code: codes.Error, Description: "input: user forbidden\n"},
+			wantEvents: []sdktrace.Event{
+				{
+					Name: "exception",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "user"),
+						attribute.String("graphql.errors.code", "FORBIDDEN"),
+						attribute.String("graphql.error.kind", "server"),
+						attribute.String("exception.type", "github.com/aereal/otelgqlgen/internal/test/resolvers.ForbiddenError"),
+						attribute.String("exception.message", "forbidden"),
+						attrStacktrace,
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, otelgqlgen.WithErrorClassifier(tc.classifier), otelgqlgen.ClassifyUnknownErrorsAsServer(tc.unknownAsServer))
+			gqlsrv.SetErrorPresenter(codeErrorPresenter)
+			_ = doRequest(ctx, t, gqlsrv, tc.params)
+			span := findSpan(t, exporter.GetSpans(), "query")
+			if diff := cmp.Diff(tc.wantStatus, span.Status); diff != "" {
+				t.Errorf("status: -want, +got:\n%s", diff)
+			}
+			opts := []cmp.Option{cmp.Transformer("attribute.KeyValue", transformKeyValue), cmpopts.IgnoreFields(sdktrace.Event{}, "Time")}
+			if diff := cmp.Diff(tc.wantEvents, span.Events, opts...); diff != "" {
+				t.Errorf("events: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package otelgqlgen_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"github.com/aereal/otelgqlgen/internal/test/resolvers"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func codeErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	var (
+		forbidden resolvers.ForbiddenError
+		notFound  *resolvers.NotFoundError
+	)
+	switch {
+	case errors.As(err, &forbidden):
+		gqlErr.Extensions = map[string]any{"code": "FORBIDDEN", "reason": map[string]any{"scope": "admin"}}
+	case errors.As(err, &notFound):
+		gqlErr.Extensions = map[string]any{"code": "NOT_FOUND"}
+	}
+	return gqlErr
+}
+
+func TestTracer_errorCodes(t *testing.T) {
+	type testCase struct {
+		name       string
+		params     *graphql.RawParams
+		options    []otelgqlgen.Option
+		wantAttrs  []attribute.KeyValue
+		wantEvents []sdktrace.Event
+	}
+	testCases := []testCase{
+		{
+			name:   "code",
+			params: &graphql.RawParams{Query: `{user(name: "not_found") {name}}`},
+			wantAttrs: []attribute.KeyValue{
+				attribute.StringSlice("graphql.errors.codes", []string{"NOT_FOUND"}),
+			},
+			wantEvents: []sdktrace.Event{
+				{
+					Name: "exception",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "user"),
+						attribute.String("graphql.errors.code", "NOT_FOUND"),
+						attribute.String("exception.type", "*resolvers.NotFoundError"),
+						attribute.String("exception.message", "not found"),
+						attrStacktrace,
+					},
+				},
+			},
+		},
+		{
+			name:    "extension keys",
+			params:  &graphql.RawParams{Query: `{user(name: "forbidden") {name}}`},
+			options: []otelgqlgen.Option{otelgqlgen.WithErrorExtensionKeys("reason", "missing")},
+			wantAttrs: []attribute.KeyValue{
+				attribute.StringSlice("graphql.errors.codes", []string{"FORBIDDEN"}),
+			},
+			wantEvents: []sdktrace.Event{
+				{
+					Name: "exception",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "user"),
+						attribute.String("graphql.errors.code", "FORBIDDEN"),
+						attribute.String("graphql.errors.extensions.reason.scope", "admin"),
+						attribute.String("exception.type", "github.com/aereal/otelgqlgen/internal/test/resolvers.ForbiddenError"),
+						attribute.String("exception.message", "forbidden"),
+						attrStacktrace,
+					},
+				},
+			},
+		},
+		{
+			name:   "distinct codes",
+			params: &graphql.RawParams{Query: `{a: user(name: "not_found") {name} b: user(name: "not_found") {name} c: user(name: "forbidden") {name}}`},
+			wantAttrs: []attribute.KeyValue{
+				attribute.StringSlice("graphql.errors.codes", []string{"FORBIDDEN", "NOT_FOUND"}),
+			},
+			wantEvents: []sdktrace.Event{
+				{
+					Name: "exception",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "a"),
+						attribute.String("graphql.errors.code", "NOT_FOUND"),
+						attribute.String("exception.type", "*resolvers.NotFoundError"),
+						attribute.String("exception.message", "not found"),
+						attrStacktrace,
+					},
+				},
+				{
+					Name: "exception",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "b"),
+						attribute.String("graphql.errors.code", "NOT_FOUND"),
+						attribute.String("exception.type", "*resolvers.NotFoundError"),
+						attribute.String("exception.message", "not found"),
+						attrStacktrace,
+					},
+				},
+				{
+					Name: "exception",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "c"),
+						attribute.String("graphql.errors.code", "FORBIDDEN"),
+						attribute.String("exception.type", "github.com/aereal/otelgqlgen/internal/test/resolvers.ForbiddenError"),
+						attribute.String("exception.message", "forbidden"),
+						attrStacktrace,
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, tc.options...)
+			gqlsrv.SetErrorPresenter(codeErrorPresenter)
+			_ = doRequest(ctx, t, gqlsrv, tc.params)
+			span := findSpan(t, exporter.GetSpans(), "query")
+			opts := []cmp.Option{
+				cmp.Transformer("attribute.KeyValue", transformKeyValue),
+				cmpopts.IgnoreFields(sdktrace.Event{}, "Time"),
+				// the root fields are resolved concurrently, so the order of the errors is not stable.
+				cmpopts.SortSlices(func(x, y string) bool { return x < y }),
+				cmpopts.SortSlices(func(x, y sdktrace.Event) bool { return fmt.Sprint(x.Attributes) < fmt.Sprint(y.Attributes) }),
+			}
+			if diff := cmp.Diff(tc.wantAttrs, attrsWithPrefix(span.Attributes, "graphql.errors."), opts...); diff != "" {
+				t.Errorf("attributes: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantEvents, span.Events, opts...); diff != "" {
+				t.Errorf("events: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package otelgqlgen
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const errorEventName = "graphql.error"
+
+// ErrorRecording tells how the error is recorded on the span.
+type ErrorRecording int
+
+const (
+	// ErrorRecordAsException records the error as an exception event with the stack trace.
+	ErrorRecordAsException ErrorRecording = iota
+	// ErrorRecordAsEvent records the error as a plain event named "graphql.error" that has the message and the path of the error.
+	ErrorRecordAsEvent
+	// ErrorIgnore does not record the error.
+	ErrorIgnore
+)
+
+// ErrorDecision is the decision how to record an error.
+type ErrorDecision struct {
+	// Recording tells how the error is recorded.
+	Recording ErrorRecording
+	// Attributes are added to the recorded event in addition to the error path.
+	Attributes []attribute.KeyValue
+	// Status is the span status set by the error.
+	//
+	// codes.Unset leaves the status as is. If the errors decide both of codes.Error and codes.Ok, codes.Error takes precedence.
+	Status codes.Code
+}
+
+// ErrorRecorder decides how to record each error in the gqlgen response.
+type ErrorRecorder interface {
+	DecideError(err error) ErrorDecision
+}
+
+// DecideError records the error as an exception and sets the span status to Error if the selector returns true. Otherwise, the error is ignored.
+func (fn ErrorSelector) DecideError(err error) ErrorDecision {
+	if !fn(err) {
+		return ErrorDecision{Recording: ErrorIgnore}
+	}
+	return ErrorDecision{Recording: ErrorRecordAsException, Status: codes.Error}
+}
+
+// WithErrorRecorder creates an Option that tells Tracer to record the errors according to the given recorder.
+//
+// It replaces the selector given by WithErrorSelector, and vice versa.
+//
+// default value: the recorder that records all of the errors as exceptions and sets the span status to Error
+func WithErrorRecorder(recorder ErrorRecorder) Option {
+	return func(c *config) { c.errorRecorder = recorder }
+}
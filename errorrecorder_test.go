@@ -0,0 +1,121 @@
+package otelgqlgen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"github.com/aereal/otelgqlgen/internal/test/resolvers"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type notFoundRecorder struct {
+	decision otelgqlgen.ErrorDecision
+}
+
+func (r notFoundRecorder) DecideError(err error) otelgqlgen.ErrorDecision {
+	if errors.Is(err, &resolvers.NotFoundError{}) {
+		return r.decision
+	}
+	return otelgqlgen.ErrorSelector(func(error) bool { return true }).DecideError(err)
+}
+
+func TestTracer_errorRecorder(t *testing.T) {
+	type testCase struct {
+		name       string
+		recorder   otelgqlgen.ErrorRecorder
+		wantStatus sdktrace.Status
+		wantEvents []sdktrace.Event
+	}
+	testCases := []testCase{
+		{
+			name: "as event",
+			recorder: notFoundRecorder{decision: otelgqlgen.ErrorDecision{
+				Recording:  otelgqlgen.ErrorRecordAsEvent,
+				Attributes: []attribute.KeyValue{attribute.Bool("app.expected", true)},
+			}},
+			wantEvents: []sdktrace.Event{
+				{
+					Name: "graphql.error",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "user"),
+						attribute.String("graphql.errors.message", "not found"),
+						attribute.Bool("app.expected", true),
+					},
+				},
+			},
+		},
+		{
+			name:     "ignored",
+			recorder: notFoundRecorder{decision: otelgqlgen.ErrorDecision{Recording: otelgqlgen.ErrorIgnore, Status: codes.Error}},
+		},
+		{
+			name: "as exception with the status",
+			recorder: notFoundRecorder{decision: otelgqlgen.ErrorDecision{
+				Recording:  otelgqlgen.ErrorRecordAsException,
+				Attributes: []attribute.KeyValue{attribute.Bool("app.expected", true)},
+				Status:     codes.Ok,
+			}},
+			wantStatus: sdktrace.Status{Code: codes.Ok},
+			wantEvents: []sdktrace.Event{
+				{
+					Name: "exception",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "user"),
+						attribute.Bool("app.expected", true),
+						attribute.String("exception.type", "*resolvers.NotFoundError"),
+						attribute.String("exception.message", "not found"),
+						attrStacktrace,
+					},
+				},
+			},
+		},
+		{
+			name:       "error selector",
+			recorder:   otelgqlgen.ErrorSelector(func(error) bool { return true }),
+			wantStatus: sdktrace.Status{Code: codes.Error, Description: "input: user not found\n"},
+			wantEvents: []sdktrace.Event{
+				{
+					Name: "exception",
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.errors.path", "user"),
+						attribute.String("exception.type", "*resolvers.NotFoundError"),
+						attribute.String("exception.message", "not found"),
+						attrStacktrace,
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, otelgqlgen.WithErrorRecorder(tc.recorder))
+			_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{Query: `{user(name: "not_found") {name}}`})
+			span := findSpan(t, exporter.GetSpans(), "query")
+			if diff := cmp.Diff(tc.wantStatus, span.Status); diff != "" {
+				t.Errorf("status: -want, +got:\n%s", diff)
+			}
+			opts := []cmp.Option{cmp.Transformer("attribute.KeyValue", transformKeyValue), cmpopts.IgnoreFields(sdktrace.Event{}, "Time")}
+			if diff := cmp.Diff(tc.wantEvents, span.Events, opts...); diff != "" {
+				t.Errorf("events: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTracer_nilErrorSelector(t *testing.T) {
+	ctx := testContext(t)
+	gqlsrv, exporter := newTestServer(t, otelgqlgen.WithErrorSelector(nil))
+	_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{Query: `{user(name: "forbidden") {name}}`})
+	span := findSpan(t, exporter.GetSpans(), "query")
+	wantStatus := sdktrace.Status{Code: codes.Error, Description: "input: user forbidden\n"}
+	if diff := cmp.Diff(wantStatus, span.Status); diff != "" {
+		t.Errorf("status: -want, +got:\n%s", diff)
+	}
+}
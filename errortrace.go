@@ -0,0 +1,82 @@
+package otelgqlgen
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type fieldSpansKey struct{}
+
+// fieldSpans holds the span contexts of the field spans per the field path during an operation.
+type fieldSpans struct {
+	byPath map[string]trace.SpanContext
+	mu     sync.Mutex
+}
+
+func withFieldSpans(ctx context.Context) (context.Context, *fieldSpans) {
+	spans := &fieldSpans{byPath: map[string]trace.SpanContext{}}
+	return context.WithValue(ctx, fieldSpansKey{}, spans), spans
+}
+
+func fieldSpansFromContext(ctx context.Context) *fieldSpans {
+	spans, _ := ctx.Value(fieldSpansKey{}).(*fieldSpans)
+	return spans
+}
+
+func (s *fieldSpans) add(path string, sc trace.SpanContext) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byPath[path] = sc
+}
+
+// find returns the span context of the field at the path or the nearest ancestor of it.
+func (s *fieldSpans) find(path ast.Path) (trace.SpanContext, bool) {
+	if s == nil {
+		return trace.SpanContext{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(path); i > 0; i-- {
+		if sc, ok := s.byPath[path[:i].String()]; ok {
+			return sc, true
+		}
+	}
+	return trace.SpanContext{}, false
+}
+
+// setErrorTraceExtensions adds the trace information of the span that the error occurred in to the extensions of each error.
+//
+// The errors are attributed to the nearest field span on the path, or the operation span if no field span is found.
+// The errors that already have the trace ID in the extensions are left as is.
+func (t Tracer) setErrorTraceExtensions(ctx context.Context, errs gqlerror.List, opSpanContext trace.SpanContext) {
+	if t.errorTraceExtension == nil {
+		return
+	}
+	spans := fieldSpansFromContext(ctx)
+	for _, gqlErr := range errs {
+		if _, ok := gqlErr.Extensions[t.errorTraceExtension.TraceIDKey]; ok {
+			continue
+		}
+		sc, ok := spans.find(gqlErr.Path)
+		if !ok {
+			sc = opSpanContext
+		}
+		if !sc.IsValid() {
+			continue
+		}
+		extensions := maps.Clone(gqlErr.Extensions)
+		if extensions == nil {
+			extensions = map[string]any{}
+		}
+		t.errorTraceExtension.set(extensions, sc)
+		gqlErr.Extensions = extensions
+	}
+}
@@ -0,0 +1,53 @@
+package otelgqlgen
+
+import (
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultTraceIDExtensionKey = "traceId"
+
+// TraceExtension tells the keys of the extensions that Tracer adds the trace information to.
+//
+// The empty keys are omitted except for TraceIDKey, which falls back to "traceId".
+type TraceExtension struct {
+	// TraceIDKey is the key of the trace ID.
+	TraceIDKey string
+
+	// SpanIDKey is the key of the span ID.
+	SpanIDKey string
+
+	// SampledKey is the key of whether the trace is sampled.
+	SampledKey string
+}
+
+func (e TraceExtension) withDefaults() TraceExtension {
+	if e.TraceIDKey == "" {
+		e.TraceIDKey = defaultTraceIDExtensionKey
+	}
+	return e
+}
+
+func (e TraceExtension) set(extensions map[string]any, sc trace.SpanContext) {
+	extensions[e.TraceIDKey] = sc.TraceID().String()
+	if e.SpanIDKey != "" {
+		extensions[e.SpanIDKey] = sc.SpanID().String()
+	}
+	if e.SampledKey != "" {
+		extensions[e.SampledKey] = sc.IsSampled()
+	}
+}
+
+// setResponseTraceExtension adds the trace information of the operation span to the response extensions.
+func (t Tracer) setResponseTraceExtension(resp *graphql.Response, sc trace.SpanContext) {
+	if t.responseTraceExtension == nil || resp == nil || !sc.IsValid() {
+		return
+	}
+	if t.traceExtensionOnError && len(resp.Errors) == 0 {
+		return
+	}
+	if resp.Extensions == nil {
+		resp.Extensions = map[string]any{}
+	}
+	t.responseTraceExtension.set(resp.Extensions, sc)
+}
@@ -0,0 +1,145 @@
+package otelgqlgen_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer_responseTraceExtension(t *testing.T) {
+	type testCase struct {
+		name        string
+		ext         otelgqlgen.TraceExtension
+		onlyOnError bool
+		userName    string
+		want        func(span tracetest.SpanStub) map[string]any
+	}
+	testCases := []testCase{
+		{
+			name:     "default key",
+			userName: "aereal",
+			want: func(span tracetest.SpanStub) map[string]any {
+				return map[string]any{"traceId": span.SpanContext.TraceID().String()}
+			},
+		},
+		{
+			name:     "custom keys",
+			ext:      otelgqlgen.TraceExtension{TraceIDKey: "trace_id", SpanIDKey: "span_id", SampledKey: "sampled"},
+			userName: "aereal",
+			want: func(span tracetest.SpanStub) map[string]any {
+				return map[string]any{
+					"trace_id": span.SpanContext.TraceID().String(),
+					"span_id":  span.SpanContext.SpanID().String(),
+					"sampled":  true,
+				}
+			},
+		},
+		{
+			name:        "only on error without errors",
+			onlyOnError: true,
+			userName:    "aereal",
+			want:        func(_ tracetest.SpanStub) map[string]any { return nil },
+		},
+		{
+			name:        "only on error with errors",
+			onlyOnError: true,
+			userName:    "forbidden",
+			want: func(span tracetest.SpanStub) map[string]any {
+				return map[string]any{"traceId": span.SpanContext.TraceID().String()}
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, otelgqlgen.WithResponseTraceExtension(tc.ext, tc.onlyOnError))
+			body := doRequest(ctx, t, gqlsrv, &graphql.RawParams{
+				Query:     `query($name: String!) {user(name: $name) {name}}`,
+				Variables: map[string]any{"name": tc.userName},
+			})
+			var resp struct {
+				Extensions map[string]any `json:"extensions"`
+			}
+			if err := json.Unmarshal(body, &resp); err != nil {
+				t.Fatal(err)
+			}
+			want := tc.want(findSpan(t, exporter.GetSpans(), "query"))
+			if diff := cmp.Diff(want, resp.Extensions); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTracer_errorTraceExtension(t *testing.T) {
+	type testCase struct {
+		name     string
+		options  []otelgqlgen.Option
+		userName string
+		wantSpan string
+	}
+	testCases := []testCase{
+		{
+			name:     "error on traced field",
+			userName: "forbidden",
+			wantSpan: "Query/user",
+		},
+		{
+			name:     "error on nested field",
+			userName: "invalid",
+			wantSpan: "User/name",
+		},
+		{
+			name:     "error on untraced field",
+			options:  []otelgqlgen.Option{otelgqlgen.WithFieldFilter(otelgqlgen.ExcludeFields("User.name"))},
+			userName: "invalid",
+			wantSpan: "Query/user",
+		},
+		{
+			name:     "no field spans",
+			options:  []otelgqlgen.Option{otelgqlgen.WithFieldFilter(otelgqlgen.ExcludeFields("*.*"))},
+			userName: "invalid",
+			wantSpan: "query",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			options := append([]otelgqlgen.Option{otelgqlgen.WithErrorTraceExtension(otelgqlgen.TraceExtension{SpanIDKey: "spanId"})}, tc.options...)
+			gqlsrv, exporter := newTestServer(t, options...)
+			body := doRequest(ctx, t, gqlsrv, &graphql.RawParams{
+				Query:     `query($name: String!) {user(name: $name) {name}}`,
+				Variables: map[string]any{"name": tc.userName},
+			})
+			var resp struct {
+				Errors []struct {
+					Extensions map[string]any `json:"extensions"`
+				} `json:"errors"`
+			}
+			if err := json.Unmarshal(body, &resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Errors) != 1 {
+				t.Fatalf("errors: want=1 got=%d\n%s", len(resp.Errors), body)
+			}
+			span := findSpan(t, exporter.GetSpans(), tc.wantSpan)
+			want := map[string]any{
+				"traceId": span.SpanContext.TraceID().String(),
+				"spanId":  span.SpanContext.SpanID().String(),
+			}
+			got := map[string]any{}
+			for k, v := range resp.Errors[0].Extensions {
+				if k == "traceId" || k == "spanId" {
+					got[k] = v
+				}
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package otelgqlgen
+
+import (
+	"slices"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+const coordinateWildcard = "*"
+
+// FieldFilter is a predicate that the field should be traced.
+//
+// Tracer creates the field span only if the function returns true.
+// Note that the fields excluded by TraceStructFields are not traced regardless of the filter.
+type FieldFilter func(fieldCtx *graphql.FieldContext) bool
+
+// IncludeFields returns a FieldFilter that traces only the fields matched with the given schema coordinates.
+//
+// The coordinate consists of the object name and the field name joined by the dot (e.g. "User.name").
+// Either of them can be the wildcard "*" (e.g. "Query.*" or "*.id").
+func IncludeFields(coordinates ...string) FieldFilter {
+	return func(fieldCtx *graphql.FieldContext) bool {
+		return matchFieldCoordinates(fieldCtx, coordinates)
+	}
+}
+
+// ExcludeFields returns a FieldFilter that traces the fields except for the ones matched with the given schema coordinates.
+//
+// See IncludeFields for the format of the coordinates.
+func ExcludeFields(coordinates ...string) FieldFilter {
+	return func(fieldCtx *graphql.FieldContext) bool {
+		return !matchFieldCoordinates(fieldCtx, coordinates)
+	}
+}
+
+func matchFieldCoordinates(fieldCtx *graphql.FieldContext, coordinates []string) bool {
+	candidates := []string{
+		fieldCtx.Object + "." + fieldCtx.Field.Name,
+		fieldCtx.Object + "." + coordinateWildcard,
+		coordinateWildcard + "." + fieldCtx.Field.Name,
+		coordinateWildcard + "." + coordinateWildcard,
+	}
+	for _, c := range candidates {
+		if slices.Contains(coordinates, c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package otelgqlgen_test
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestTracer_fieldFilter(t *testing.T) {
+	type testCase struct {
+		name   string
+		filter otelgqlgen.FieldFilter
+		want   []string
+	}
+	testCases := []testCase{
+		{
+			name: "no filter",
+			want: []string{"Query/user", "User/age", "User/isAdmin", "User/name"},
+		},
+		{
+			name:   "custom filter",
+			filter: func(fieldCtx *graphql.FieldContext) bool { return fieldCtx.IsResolver },
+			want:   []string{"Query/user", "User/age", "User/name"},
+		},
+		{
+			name:   "exclude field",
+			filter: otelgqlgen.ExcludeFields("User.name"),
+			want:   []string{"Query/user", "User/age", "User/isAdmin"},
+		},
+		{
+			name:   "exclude type",
+			filter: otelgqlgen.ExcludeFields("User.*"),
+			want:   []string{"Query/user"},
+		},
+		{
+			name:   "include fields",
+			filter: otelgqlgen.IncludeFields("Query.*", "*.age"),
+			want:   []string{"Query/user", "User/age"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			options := []otelgqlgen.Option{otelgqlgen.ShouldTraceCaptureTimings(false), otelgqlgen.TraceStructFields(true)}
+			if tc.filter != nil {
+				options = append(options, otelgqlgen.WithFieldFilter(tc.filter))
+			}
+			gqlsrv, exporter := newTestServer(t, options...)
+			_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{Query: `{user(name: "aereal") {name age isAdmin}}`})
+			got := make([]string, 0)
+			for _, span := range exporter.GetSpans() {
+				if span.Name == "query" {
+					continue
+				}
+				got = append(got, span.Name)
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.SortSlices(func(x, y string) bool { return x < y })); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
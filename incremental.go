@@ -0,0 +1,135 @@
+package otelgqlgen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	deferDirectiveName = "defer"
+	payloadEventName   = "graphql.payload"
+)
+
+type incrementalDeliveryKey struct{}
+
+// incrementalDelivery holds the operation span that lasts until the last payload is delivered.
+type incrementalDelivery struct {
+	span     trace.Span
+	parent   trace.Span
+	errors   gqlerror.List
+	payloads int
+	mu       sync.Mutex
+	once     sync.Once
+}
+
+func withIncrementalDelivery(ctx context.Context, inc *incrementalDelivery) context.Context {
+	return context.WithValue(ctx, incrementalDeliveryKey{}, inc)
+}
+
+func incrementalDeliveryFromContext(ctx context.Context) *incrementalDelivery {
+	inc, _ := ctx.Value(incrementalDeliveryKey{}).(*incrementalDelivery)
+	return inc
+}
+
+// hasDeferredFragments returns whether the selection set has any fragment annotated with @defer.
+func hasDeferredFragments(set ast.SelectionSet) bool {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if hasDeferredFragments(sel.SelectionSet) {
+				return true
+			}
+		case *ast.InlineFragment:
+			if sel.Directives.ForName(deferDirectiveName) != nil || hasDeferredFragments(sel.SelectionSet) {
+				return true
+			}
+		case *ast.FragmentSpread:
+			if sel.Directives.ForName(deferDirectiveName) != nil {
+				return true
+			}
+			if sel.Definition != nil && hasDeferredFragments(sel.Definition.SelectionSet) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// interceptIncrementalDelivery starts the operation span and ends it when the last payload is delivered.
+//
+// The deferred fields are resolved with the context of the first payload, so the operation span must be started before it.
+func (t Tracer) interceptIncrementalDelivery(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	parentSpan := trace.SpanFromContext(ctx)
+	startedAt := graphql.GetOperationContext(ctx).Stats.OperationStart
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+	ctx, span := t.startResponseSpan(ctx)
+	if span.IsRecording() {
+		if t.shouldTraceCaptureTimings {
+			t.captureOperationTimings(ctx)
+		}
+		span.SetAttributes(t.attrsOperation(ctx)...)
+	}
+	ctx, finish := t.withResponseState(ctx)
+	inc := &incrementalDelivery{span: span, parent: parentSpan}
+	ctx = withIncrementalDelivery(ctx, inc)
+	end := func() {
+		inc.once.Do(func() {
+			finish(span)
+			inc.mu.Lock()
+			errs := inc.errors
+			inc.mu.Unlock()
+			t.recordParentSpanErrors(parentSpan, errs)
+			t.recordOperationMetrics(ctx, startedAt, &graphql.Response{Errors: errs})
+			span.End()
+		})
+	}
+	// the transports that do not support the incremental delivery never request the subsequent payloads.
+	stop := context.AfterFunc(ctx, end)
+	handler := next(ctx)
+	return func(ctx context.Context) *graphql.Response {
+		resp := handler(ctx)
+		if resp != nil {
+			inc.record(t, resp)
+			t.setErrorTraceExtensions(ctx, resp.Errors, span.SpanContext())
+			t.setResponseTraceExtension(resp, span.SpanContext())
+		}
+		if resp == nil || resp.HasNext == nil || !*resp.HasNext {
+			stop()
+			end()
+		}
+		return resp
+	}
+}
+
+func (inc *incrementalDelivery) record(t Tracer, resp *graphql.Response) {
+	inc.mu.Lock()
+	defer inc.mu.Unlock()
+	inc.payloads++
+	inc.errors = append(inc.errors, resp.Errors...)
+	attrs := []attribute.KeyValue{
+		keyPayloadSequence.Int(inc.payloads),
+		keyPayloadErrors.Int(len(resp.Errors)),
+	}
+	if resp.Label != "" {
+		attrs = append(attrs, keyPayloadLabel.String(resp.Label))
+	}
+	if len(resp.Path) > 0 {
+		attrs = append(attrs, keyPayloadPath.String(resp.Path.String()))
+	}
+	if resp.HasNext != nil {
+		attrs = append(attrs, keyPayloadHasNext.Bool(*resp.HasNext))
+	}
+	inc.span.AddEvent(payloadEventName, trace.WithAttributes(attrs...))
+	if len(resp.Errors) > 0 && t.recordsOperationSpanErrors(inc.parent) {
+		t.recordGQLErrors(inc.span, resp.Errors)
+	}
+}
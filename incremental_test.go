@@ -0,0 +1,100 @@
+package otelgqlgen_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/aereal/otelgqlgen"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestTracer_traceIncrementalDelivery(t *testing.T) {
+	type testCase struct {
+		name        string
+		userName    string
+		wantStatus  codes.Code
+		wantEvents  []map[attribute.Key]any
+		wantParents map[string]string
+	}
+	testCases := []testCase{
+		{
+			name:       "ok",
+			userName:   "aereal",
+			wantStatus: codes.Unset,
+			wantEvents: []map[attribute.Key]any{
+				{"graphql.payload.sequence": int64(1), "graphql.payload.errors": int64(0), "graphql.payload.has_next": true},
+				{"graphql.payload.sequence": int64(2), "graphql.payload.errors": int64(0), "graphql.payload.has_next": false, "graphql.payload.label": "details", "graphql.payload.path": "user"},
+			},
+			wantParents: map[string]string{"Query/user": "query", "User/name": "Query/user", "User/age": "Query/user"},
+		},
+		{
+			name:       "deferred errors",
+			userName:   "invalid",
+			wantStatus: codes.Error,
+			wantEvents: []map[attribute.Key]any{
+				{"graphql.payload.sequence": int64(1), "graphql.payload.errors": int64(0), "graphql.payload.has_next": true},
+				{"graphql.payload.sequence": int64(2), "graphql.payload.errors": int64(2), "graphql.payload.has_next": false, "graphql.payload.label": "details", "graphql.payload.path": "user"},
+			},
+			wantParents: map[string]string{"Query/user": "query", "User/name": "Query/user", "User/age": "Query/user"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServerWithTransport(t, transport.MultipartMixed{}, otelgqlgen.ShouldTraceCaptureTimings(false), otelgqlgen.TraceIncrementalDelivery(true))
+			_ = doRequestWithHeader(ctx, t, gqlsrv,
+				&graphql.RawParams{
+					Query:     `query($name: String!) {user(name: $name) {isAdmin ... @defer(label: "details") {name age}}}`,
+					Variables: map[string]any{"name": tc.userName},
+				},
+				http.Header{"Accept": []string{"multipart/mixed"}})
+			spans := exporter.GetSpans()
+			opSpans := 0
+			names := map[string]string{}
+			for _, span := range spans {
+				names[span.SpanContext.SpanID().String()] = span.Name
+				if span.Name == "query" {
+					opSpans++
+				}
+			}
+			if opSpans != 1 {
+				t.Fatalf("operation spans: want=1 got=%d", opSpans)
+			}
+			opSpan := findSpan(t, spans, "query")
+			if opSpan.Status.Code != tc.wantStatus {
+				t.Errorf("status: want=%s got=%s", tc.wantStatus, opSpan.Status.Code)
+			}
+			gotEvents := make([]map[attribute.Key]any, 0, len(opSpan.Events))
+			for _, ev := range opSpan.Events {
+				if ev.Name != "graphql.payload" {
+					continue
+				}
+				attrs := map[attribute.Key]any{}
+				for _, attr := range ev.Attributes {
+					attrs[attr.Key] = attr.Value.AsInterface()
+				}
+				gotEvents = append(gotEvents, attrs)
+			}
+			if diff := cmp.Diff(tc.wantEvents, gotEvents); diff != "" {
+				t.Errorf("events: -want, +got:\n%s", diff)
+			}
+			gotParents := map[string]string{}
+			for _, span := range spans {
+				if span.Name == "query" {
+					continue
+				}
+				gotParents[span.Name] = names[span.Parent.SpanID().String()]
+				if span.EndTime.After(opSpan.EndTime) {
+					t.Errorf("span %s ends after the operation span", span.Name)
+				}
+			}
+			if diff := cmp.Diff(tc.wantParents, gotParents); diff != "" {
+				t.Errorf("parents: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
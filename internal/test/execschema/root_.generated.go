@@ -35,6 +35,7 @@ type Config struct {
 type ResolverRoot interface {
 	Mutation() MutationResolver
 	Query() QueryResolver
+	Subscription() SubscriptionResolver
 	User() UserResolver
 }
 
@@ -44,11 +45,19 @@ type DirectiveRoot struct {
 type ComplexityRoot struct {
 	Mutation struct {
 		RegisterUser func(childComplexity int, name string) int
+		SignIn       func(childComplexity int, name string, password string) int
+		SignInWith   func(childComplexity int, credentials model.Credentials) int
 	}
 
 	Query struct {
-		Root func(childComplexity int, num *int, rootInput *model.RootInput) int
-		User func(childComplexity int, name string) int
+		Root   func(childComplexity int, num *int, rootInput *model.RootInput) int
+		Search func(childComplexity int, limit int, ratio *float64, exact *bool, tags []string) int
+		User   func(childComplexity int, name string) int
+		Users  func(childComplexity int, names []string) int
+	}
+
+	Subscription struct {
+		UserUpdated func(childComplexity int, names []string) int
 	}
 
 	User struct {
@@ -89,6 +98,30 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.Mutation.RegisterUser(childComplexity, args["name"].(string)), true
 
+	case "Mutation.signIn":
+		if e.complexity.Mutation.SignIn == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_signIn_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.SignIn(childComplexity, args["name"].(string), args["password"].(string)), true
+
+	case "Mutation.signInWith":
+		if e.complexity.Mutation.SignInWith == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_signInWith_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.SignInWith(childComplexity, args["credentials"].(model.Credentials)), true
+
 	case "Query.root":
 		if e.complexity.Query.Root == nil {
 			break
@@ -101,6 +134,18 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.Query.Root(childComplexity, args["num"].(*int), args["rootInput"].(*model.RootInput)), true
 
+	case "Query.search":
+		if e.complexity.Query.Search == nil {
+			break
+		}
+
+		args, err := ec.field_Query_search_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.Search(childComplexity, args["limit"].(int), args["ratio"].(*float64), args["exact"].(*bool), args["tags"].([]string)), true
+
 	case "Query.user":
 		if e.complexity.Query.User == nil {
 			break
@@ -113,6 +158,30 @@ func (e *executableSchema) Complexity(ctx context.Context, typeName, field strin
 
 		return e.complexity.Query.User(childComplexity, args["name"].(string)), true
 
+	case "Query.users":
+		if e.complexity.Query.Users == nil {
+			break
+		}
+
+		args, err := ec.field_Query_users_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.Users(childComplexity, args["names"].([]string)), true
+
+	case "Subscription.userUpdated":
+		if e.complexity.Subscription.UserUpdated == nil {
+			break
+		}
+
+		args, err := ec.field_Subscription_userUpdated_args(ctx, rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Subscription.UserUpdated(childComplexity, args["names"].([]string)), true
+
 	case "User.age":
 		if e.complexity.User.Age == nil {
 			break
@@ -142,6 +211,7 @@ func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
 	opCtx := graphql.GetOperationContext(ctx)
 	ec := executionContext{opCtx, e, 0, 0, make(chan graphql.DeferredResult)}
 	inputUnmarshalMap := graphql.BuildUnmarshalerMap(
+		ec.unmarshalInputCredentials,
 		ec.unmarshalInputNestedInput,
 		ec.unmarshalInputRootInput,
 	)
@@ -189,6 +259,23 @@ func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
 			var buf bytes.Buffer
 			data.MarshalGQL(&buf)
 
+			return &graphql.Response{
+				Data: buf.Bytes(),
+			}
+		}
+	case ast.Subscription:
+		next := ec._Subscription(ctx, opCtx.Operation.SelectionSet)
+
+		var buf bytes.Buffer
+		return func(ctx context.Context) *graphql.Response {
+			buf.Reset()
+			data := next(ctx)
+
+			if data == nil {
+				return nil
+			}
+			data.MarshalGQL(&buf)
+
 			return &graphql.Response{
 				Data: buf.Bytes(),
 			}
@@ -246,6 +333,8 @@ var sources = []*ast.Source{
 	name: String
 ) on INPUT_FIELD_DEFINITION | FIELD_DEFINITION
 
+directive @sensitive on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+
 type User {
   name: String! @goField(forceResolver: true)
   age: Int @goField(forceResolver: true)
@@ -256,17 +345,30 @@ input NestedInput {
   val: String
 }
 
+input Credentials {
+  name: String!
+  password: String! @sensitive
+}
+
 input RootInput {
   nested: NestedInput! = {val: ""}
 }
 
 type Query {
   user(name: String!): User
+  users(names: [String!]!): [User!]!
   root(num: Int, rootInput: RootInput = {nested: {}}): Boolean!
+  search(limit: Int!, ratio: Float, exact: Boolean, tags: [String!]): Boolean!
 }
 
 type Mutation {
   registerUser(name: String!): Boolean!
+  signIn(name: String!, password: String! @sensitive): Boolean!
+  signInWith(credentials: Credentials!): Boolean!
+}
+
+type Subscription {
+  userUpdated(names: [String!]!): User!
 }
 `, BuiltIn: false},
 }
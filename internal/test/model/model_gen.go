@@ -2,6 +2,11 @@
 
 package model
 
+type Credentials struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
 type Mutation struct {
 }
 
@@ -16,6 +21,9 @@ type RootInput struct {
 	Nested *NestedInput `json:"nested"`
 }
 
+type Subscription struct {
+}
+
 type User struct {
 	Name    string `json:"name"`
 	Age     *int   `json:"age,omitempty"`
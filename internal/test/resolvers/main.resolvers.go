@@ -8,6 +8,7 @@ package resolvers
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/aereal/otelgqlgen/internal/test/execschema"
 	"github.com/aereal/otelgqlgen/internal/test/model"
@@ -18,6 +19,16 @@ func (r *mutationResolver) RegisterUser(ctx context.Context, name string) (bool,
 	return true, nil
 }
 
+// SignIn is the resolver for the signIn field.
+func (r *mutationResolver) SignIn(ctx context.Context, name string, password string) (bool, error) {
+	return password != "", nil
+}
+
+// SignInWith is the resolver for the signInWith field.
+func (r *mutationResolver) SignInWith(ctx context.Context, credentials model.Credentials) (bool, error) {
+	return credentials.Password != "", nil
+}
+
 // User is the resolver for the user field.
 func (r *queryResolver) User(ctx context.Context, name string) (*model.User, error) {
 	if name == "forbidden" {
@@ -26,15 +37,52 @@ func (r *queryResolver) User(ctx context.Context, name string) (*model.User, err
 	if name == "not_found" {
 		return nil, &NotFoundError{}
 	}
+	if name == "multiple" {
+		return nil, fmt.Errorf("user: %w", errors.Join(ForbiddenError{}, &NotFoundError{}))
+	}
 	age := 17
 	return &model.User{Name: name, Age: &age}, nil
 }
 
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, names []string) ([]*model.User, error) {
+	users := make([]*model.User, 0, len(names))
+	for _, name := range names {
+		user, err := r.User(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
 // Root is the resolver for the root field.
 func (r *queryResolver) Root(ctx context.Context, num *int, rootInput *model.RootInput) (bool, error) {
 	return true, nil
 }
 
+// Search is the resolver for the search field.
+func (r *queryResolver) Search(ctx context.Context, limit int, ratio *float64, exact *bool, tags []string) (bool, error) {
+	return len(tags) < limit, nil
+}
+
+// UserUpdated is the resolver for the userUpdated field.
+func (r *subscriptionResolver) UserUpdated(ctx context.Context, names []string) (<-chan *model.User, error) {
+	ch := make(chan *model.User)
+	go func() {
+		defer close(ch)
+		for _, name := range names {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- &model.User{Name: name}:
+			}
+		}
+	}()
+	return ch, nil
+}
+
 // Name is the resolver for the name field.
 func (r *userResolver) Name(ctx context.Context, obj *model.User) (string, error) {
 	if obj.Name == "invalid" {
@@ -58,9 +106,13 @@ func (r *Resolver) Mutation() execschema.MutationResolver { return &mutationReso
 // Query returns execschema.QueryResolver implementation.
 func (r *Resolver) Query() execschema.QueryResolver { return &queryResolver{r} }
 
+// Subscription returns execschema.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() execschema.SubscriptionResolver { return &subscriptionResolver{r} }
+
 // User returns execschema.UserResolver implementation.
 func (r *Resolver) User() execschema.UserResolver { return &userResolver{r} }
 
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
 type userResolver struct{ *Resolver }
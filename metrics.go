@@ -0,0 +1,111 @@
+package otelgqlgen
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	metricOperationDuration = "graphql.operation.duration"
+	metricOperationRequests = "graphql.operation.requests"
+	metricOperationErrors   = "graphql.operation.errors"
+	metricResolverDuration  = "graphql.resolver.duration"
+)
+
+// durationBuckets is the explicit bucket boundaries in seconds for the duration histograms.
+//
+// The default boundaries of the SDK are designed for milliseconds, so most of the durations would fall into the first bucket.
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+type instruments struct {
+	operationDuration metric.Float64Histogram
+	operationRequests metric.Int64Counter
+	operationErrors   metric.Int64Counter
+	resolverDuration  metric.Float64Histogram
+}
+
+func newInstruments(meter metric.Meter) instruments {
+	var (
+		inst instruments
+		err  error
+	)
+	inst.operationDuration, err = meter.Float64Histogram(metricOperationDuration,
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+		metric.WithDescription("Duration of GraphQL operations."))
+	if err != nil {
+		otel.Handle(err)
+		inst.operationDuration = noop.Float64Histogram{}
+	}
+	inst.operationRequests, err = meter.Int64Counter(metricOperationRequests,
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of GraphQL operations."))
+	if err != nil {
+		otel.Handle(err)
+		inst.operationRequests = noop.Int64Counter{}
+	}
+	inst.operationErrors, err = meter.Int64Counter(metricOperationErrors,
+		metric.WithUnit("{error}"),
+		metric.WithDescription("Number of GraphQL errors returned by operations."))
+	if err != nil {
+		otel.Handle(err)
+		inst.operationErrors = noop.Int64Counter{}
+	}
+	inst.resolverDuration, err = meter.Float64Histogram(metricResolverDuration,
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+		metric.WithDescription("Duration of GraphQL field resolvers."))
+	if err != nil {
+		otel.Handle(err)
+		inst.resolverDuration = noop.Float64Histogram{}
+	}
+	return inst
+}
+
+func (t Tracer) recordOperationMetrics(ctx context.Context, startedAt time.Time, resp *graphql.Response) {
+	// the nil response means the end of the subscription, not a response to be measured.
+	if resp == nil {
+		return
+	}
+	opCtx := graphql.GetOperationContext(ctx)
+	attrs := make([]attribute.KeyValue, 0, 2)
+	attrs = append(attrs, semconv.GraphqlOperationNameKey.String(operationName(opCtx)))
+	if op := opCtx.Operation; op != nil {
+		attrs = append(attrs, semconv.GraphqlOperationTypeKey.String(string(op.Operation)))
+	}
+	opt := metric.WithAttributeSet(attribute.NewSet(attrs...))
+	t.instruments.operationDuration.Record(ctx, time.Since(startedAt).Seconds(), opt)
+	t.instruments.operationRequests.Add(ctx, 1, opt)
+	errorsCount := map[string]int64{}
+	for _, gqlErr := range resp.Errors {
+		if t.decideError(gqlErr).Recording != ErrorIgnore {
+			errorsCount[errorCode(gqlErr)]++
+		}
+	}
+	for code, count := range errorsCount {
+		if code == "" {
+			t.instruments.operationErrors.Add(ctx, count, opt)
+			continue
+		}
+		errAttrs := append(slices.Clone(attrs), keyErrorCode.String(code))
+		t.instruments.operationErrors.Add(ctx, count, metric.WithAttributeSet(attribute.NewSet(errAttrs...)))
+	}
+}
+
+func (t Tracer) recordResolverMetrics(ctx context.Context, fieldCtx *graphql.FieldContext, startedAt time.Time) {
+	attrs := attribute.NewSet(
+		keyResolverObject.String(fieldCtx.Field.ObjectDefinition.Name),
+		keyResolverFieldName.String(fieldCtx.Field.Name),
+		keyFieldIsMethod.Bool(fieldCtx.IsMethod),
+		keyFieldIsResolver.Bool(fieldCtx.IsResolver),
+	)
+	t.instruments.resolverDuration.Record(ctx, time.Since(startedAt).Seconds(), metric.WithAttributeSet(attrs))
+}
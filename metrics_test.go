@@ -0,0 +1,307 @@
+package otelgqlgen_test
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/aereal/otelgqlgen"
+	"github.com/aereal/otelgqlgen/internal/test/execschema"
+	"github.com/aereal/otelgqlgen/internal/test/resolvers"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer_metrics(t *testing.T) {
+	type testCase struct {
+		name           string
+		params         []*graphql.RawParams
+		options        []otelgqlgen.Option
+		errorPresenter graphql.ErrorPresenterFunc
+		want           map[string][]metricPoint
+	}
+	testCases := []testCase{
+		{
+			name: "ok",
+			params: []*graphql.RawParams{
+				{Query: `query($name: String!) {user(name: $name) {name}}`, Variables: map[string]any{"name": "aereal"}},
+				{Query: `query namedOp($name: String!) {user(name: $name) {name}}`, Variables: map[string]any{"name": "aereal"}},
+				{Query: `query namedOp($name: String!) {user(name: $name) {name}}`, Variables: map[string]any{"name": "aereal"}},
+			},
+			want: map[string][]metricPoint{
+				"graphql.operation.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "namedOp", "graphql.operation.type": "query"}, Value: uint64(2)},
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: uint64(1)},
+				},
+				"graphql.resolver.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "Query", "graphql.resolver.field": "user", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(3)},
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "User", "graphql.resolver.field": "name", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(3)},
+				},
+				"graphql.operation.requests": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "namedOp", "graphql.operation.type": "query"}, Value: int64(2)},
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: int64(1)},
+				},
+			},
+		},
+		{
+			name: "errors",
+			params: []*graphql.RawParams{
+				{Query: `query($name: String!) {user(name: $name) {name age}}`, Variables: map[string]any{"name": "invalid"}},
+			},
+			want: map[string][]metricPoint{
+				"graphql.operation.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: uint64(1)},
+				},
+				"graphql.resolver.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "Query", "graphql.resolver.field": "user", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(1)},
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "User", "graphql.resolver.field": "name", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(1)},
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "User", "graphql.resolver.field": "age", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(1)},
+				},
+				"graphql.operation.requests": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: int64(1)},
+				},
+				"graphql.operation.errors": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: int64(2)},
+				},
+			},
+		},
+		{
+			name: "error codes",
+			params: []*graphql.RawParams{
+				{Query: `query($name: String!) {user(name: $name) {name}}`, Variables: map[string]any{"name": "forbidden"}},
+				{Query: `query($name: String!) {user(name: $name) {name age}}`, Variables: map[string]any{"name": "invalid"}},
+			},
+			errorPresenter: codeErrorPresenter,
+			want: map[string][]metricPoint{
+				"graphql.operation.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: uint64(2)},
+				},
+				"graphql.resolver.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "Query", "graphql.resolver.field": "user", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(2)},
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "User", "graphql.resolver.field": "name", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(1)},
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "User", "graphql.resolver.field": "age", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(1)},
+				},
+				"graphql.operation.requests": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: int64(2)},
+				},
+				"graphql.operation.errors": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query", "graphql.errors.code": "FORBIDDEN"}, Value: int64(1)},
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: int64(2)},
+				},
+			},
+		},
+		{
+			name: "filtered fields",
+			params: []*graphql.RawParams{
+				{Query: `query($name: String!) {user(name: $name) {name}}`, Variables: map[string]any{"name": "aereal"}},
+			},
+			options: []otelgqlgen.Option{otelgqlgen.WithFieldFilter(otelgqlgen.ExcludeFields("User.name"))},
+			want: map[string][]metricPoint{
+				"graphql.operation.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: uint64(1)},
+				},
+				"graphql.resolver.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "Query", "graphql.resolver.field": "user", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(1)},
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "User", "graphql.resolver.field": "name", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(1)},
+				},
+				"graphql.operation.requests": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: int64(1)},
+				},
+			},
+		},
+		{
+			name: "fields deeper than max depth",
+			params: []*graphql.RawParams{
+				{Query: `query($name: String!) {user(name: $name) {name}}`, Variables: map[string]any{"name": "aereal"}},
+			},
+			options: []otelgqlgen.Option{otelgqlgen.WithMaxFieldDepth(1)},
+			want: map[string][]metricPoint{
+				"graphql.operation.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: uint64(1)},
+				},
+				"graphql.resolver.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "Query", "graphql.resolver.field": "user", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(1)},
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "User", "graphql.resolver.field": "name", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(1)},
+				},
+				"graphql.operation.requests": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: int64(1)},
+				},
+			},
+		},
+		{
+			name: "not sampled",
+			params: []*graphql.RawParams{
+				{Query: `query($name: String!) {user(name: $name) {name}}`, Variables: map[string]any{"name": "forbidden"}},
+			},
+			options: []otelgqlgen.Option{
+				otelgqlgen.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample()))),
+			},
+			want: map[string][]metricPoint{
+				"graphql.operation.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: uint64(1)},
+				},
+				"graphql.resolver.duration": {
+					{Attributes: map[attribute.Key]any{"graphql.resolver.object": "Query", "graphql.resolver.field": "user", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(1)},
+				},
+				"graphql.operation.requests": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: int64(1)},
+				},
+				"graphql.operation.errors": {
+					{Attributes: map[attribute.Key]any{"graphql.operation.name": "query", "graphql.operation.type": "query"}, Value: int64(1)},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			reader := sdkmetric.NewManualReader()
+			mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+			gqlsrv, _ := newTestServer(t, append(slices.Clone(tc.options), otelgqlgen.WithMeterProvider(mp))...)
+			if tc.errorPresenter != nil {
+				gqlsrv.SetErrorPresenter(tc.errorPresenter)
+			}
+			for _, params := range tc.params {
+				_ = doRequest(ctx, t, gqlsrv, params)
+			}
+			var rm metricdata.ResourceMetrics
+			if err := reader.Collect(ctx, &rm); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmpMetrics(tc.want, flattenMetrics(rm)); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTracer_metrics_subscription(t *testing.T) {
+	const interval = 100 * time.Millisecond
+	ctx := testContext(t)
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(tracetest.NewInMemoryExporter()))
+	gqlsrv := handler.New(execschema.NewExecutableSchema(execschema.Config{Resolvers: slowSubscriptionRoot{Resolver: &resolvers.Resolver{}, interval: interval}}))
+	gqlsrv.AddTransport(transport.SSE{})
+	gqlsrv.Use(otelgqlgen.New(otelgqlgen.WithTracerProvider(tp), otelgqlgen.WithMeterProvider(mp)))
+	_ = doRequestWithHeader(ctx, t, gqlsrv,
+		&graphql.RawParams{Query: `subscription {userUpdated(names: ["a", "b"]) {name}}`},
+		http.Header{"Accept": []string{"text/event-stream"}})
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]metricPoint{
+		"graphql.operation.duration": {
+			{Attributes: map[attribute.Key]any{"graphql.operation.name": "subscription", "graphql.operation.type": "subscription"}, Value: uint64(2)},
+		},
+		"graphql.resolver.duration": {
+			{Attributes: map[attribute.Key]any{"graphql.resolver.object": "Subscription", "graphql.resolver.field": "userUpdated", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(1)},
+			{Attributes: map[attribute.Key]any{"graphql.resolver.object": "User", "graphql.resolver.field": "name", "graphql.resolver.is_method": true, "graphql.resolver.is_resolver": true}, Value: uint64(2)},
+		},
+		"graphql.operation.requests": {
+			{Attributes: map[attribute.Key]any{"graphql.operation.name": "subscription", "graphql.operation.type": "subscription"}, Value: int64(2)},
+		},
+	}
+	if diff := cmpMetrics(want, flattenMetrics(rm)); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			data, ok := m.Data.(metricdata.Histogram[float64])
+			if !ok || m.Name != "graphql.operation.duration" {
+				continue
+			}
+			for _, dp := range data.DataPoints {
+				if v, ok := dp.Max.Value(); ok && v >= (interval/2).Seconds() {
+					t.Errorf("operation duration: max=%fs; each event must be measured from its arrival", v)
+				}
+			}
+		}
+	}
+}
+
+func TestTracer_metrics_durationBuckets(t *testing.T) {
+	ctx := testContext(t)
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	gqlsrv, _ := newTestServer(t, otelgqlgen.WithMeterProvider(mp))
+	_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{Query: `{user(name: "aereal") {name}}`})
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	wantBounds := []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+	for _, name := range []string{"graphql.operation.duration", "graphql.resolver.duration"} {
+		dp := findHistogramDataPoint(t, rm, name)
+		if diff := cmp.Diff(wantBounds, dp.Bounds); diff != "" {
+			t.Errorf("%s: bounds: -want, +got:\n%s", name, diff)
+		}
+		// the fast operation and resolvers fall into one of the buckets up to 100ms, not into the bucket of (0s, 5s] of the default boundaries.
+		idx := slices.Index(dp.BucketCounts, 1)
+		if idx < 0 || idx >= len(dp.Bounds) || dp.Bounds[idx] > 0.1 {
+			t.Errorf("%s: bucket counts: %v", name, dp.BucketCounts)
+		}
+	}
+}
+
+func findHistogramDataPoint(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.HistogramDataPoint[float64] {
+	t.Helper()
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if data, ok := m.Data.(metricdata.Histogram[float64]); ok && m.Name == name && len(data.DataPoints) > 0 {
+				return data.DataPoints[0]
+			}
+		}
+	}
+	t.Fatalf("histogram %q not found", name)
+	return metricdata.HistogramDataPoint[float64]{}
+}
+
+// metricPoint is a simplified data point: Value is the sum of counters or the count of histograms.
+type metricPoint struct {
+	Attributes map[attribute.Key]any
+	Value      any
+}
+
+func flattenMetrics(rm metricdata.ResourceMetrics) map[string][]metricPoint {
+	ret := map[string][]metricPoint{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					ret[m.Name] = append(ret[m.Name], metricPoint{Attributes: attrsToMap(dp.Attributes), Value: dp.Value})
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					ret[m.Name] = append(ret[m.Name], metricPoint{Attributes: attrsToMap(dp.Attributes), Value: dp.Count})
+				}
+			}
+		}
+	}
+	return ret
+}
+
+func attrsToMap(set attribute.Set) map[attribute.Key]any {
+	m := make(map[attribute.Key]any, set.Len())
+	for _, kv := range set.ToSlice() {
+		m[kv.Key] = kv.Value.AsInterface()
+	}
+	return m
+}
+
+func cmpMetrics(want, got map[string][]metricPoint) string {
+	return cmp.Diff(want, got,
+		cmpopts.SortSlices(func(x, y metricPoint) bool { return fmt.Sprint(x.Attributes) < fmt.Sprint(y.Attributes) }))
+}
@@ -0,0 +1,51 @@
+package otelgqlgen_test
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestTracer_multiErrors(t *testing.T) {
+	ctx := testContext(t)
+	gqlsrv, exporter := newTestServer(t)
+	_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{Query: `{user(name: "multiple") {name}}`})
+	span := findSpan(t, exporter.GetSpans(), "query")
+	wantStatus := sdktrace.Status{Code: codes.Error, Description: "input: user user: forbidden\nnot found\n"}
+	if diff := cmp.Diff(wantStatus, span.Status); diff != "" {
+		t.Errorf("status: -want, +got:\n%s", diff)
+	}
+	wantEvents := []sdktrace.Event{
+		{
+			Name: "exception",
+			Attributes: []attribute.KeyValue{
+				attribute.String("graphql.errors.path", "user"),
+				attribute.Int("graphql.errors.leaf.index", 0),
+				attribute.Int("graphql.errors.leaf.count", 2),
+				attribute.String("exception.type", "github.com/aereal/otelgqlgen/internal/test/resolvers.ForbiddenError"),
+				attribute.String("exception.message", "forbidden"),
+				attrStacktrace,
+			},
+		},
+		{
+			Name: "exception",
+			Attributes: []attribute.KeyValue{
+				attribute.String("graphql.errors.path", "user"),
+				attribute.Int("graphql.errors.leaf.index", 1),
+				attribute.Int("graphql.errors.leaf.count", 2),
+				attribute.String("exception.type", "*resolvers.NotFoundError"),
+				attribute.String("exception.message", "not found"),
+				attrStacktrace,
+			},
+		},
+	}
+	opts := []cmp.Option{cmp.Transformer("attribute.KeyValue", transformKeyValue), cmpopts.IgnoreFields(sdktrace.Event{}, "Time")}
+	if diff := cmp.Diff(wantEvents, span.Events, opts...); diff != "" {
+		t.Errorf("events: -want, +got:\n%s", diff)
+	}
+}
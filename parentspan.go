@@ -0,0 +1,60 @@
+package otelgqlgen
+
+import (
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ParentSpanErrors tells how Tracer propagates the GraphQL errors onto the parent span of the operation span (e.g. the HTTP server span).
+type ParentSpanErrors int
+
+const (
+	// ParentSpanErrorsRecord records the errors on both of the operation span and the parent span.
+	ParentSpanErrorsRecord ParentSpanErrors = iota
+	// ParentSpanErrorsOnly records the errors only on the parent span and not on the operation span.
+	// The errors are recorded on the operation span if there is no parent span, so that they are not dropped.
+	ParentSpanErrorsOnly
+	// ParentSpanErrorsSummary records the errors on the operation span and only sets the count of them on the parent span.
+	ParentSpanErrorsSummary
+	// ParentSpanErrorsNone records the errors only on the operation span.
+	ParentSpanErrorsNone
+)
+
+// WithParentSpanErrors creates an Option that tells Tracer to propagate the GraphQL errors onto the parent span in the given mode.
+//
+// default value: ParentSpanErrorsRecord
+// The parent span is the span in the context of the operation if it is valid. The errors are not propagated if there is no parent span,
+// and ParentSpanErrorsOnly falls back to recording them on the operation span.
+// The errors of the subscription traced with the lifetime span (see WithSubscriptionMode) are propagated when the subscription ends.
+func WithParentSpanErrors(mode ParentSpanErrors) Option {
+	return func(c *config) { c.parentSpanErrors = mode }
+}
+
+// recordParentSpanErrors records the errors on the parent span according to the mode.
+func (t Tracer) recordParentSpanErrors(parent trace.Span, errs gqlerror.List) {
+	if len(errs) == 0 || !parent.SpanContext().IsValid() {
+		return
+	}
+	switch t.parentSpanErrors {
+	case ParentSpanErrorsRecord, ParentSpanErrorsOnly:
+		t.recordGQLErrors(parent, errs)
+	case ParentSpanErrorsSummary:
+		var count int
+		for _, gqlErr := range errs {
+			if t.decideError(gqlErr).Recording != ErrorIgnore {
+				count++
+			}
+		}
+		if count > 0 {
+			parent.SetAttributes(keyErrorsCount.Int(count))
+		}
+	case ParentSpanErrorsNone:
+	}
+}
+
+// recordsOperationSpanErrors returns whether the errors are recorded on the operation span.
+//
+// The operation span records the errors instead of the parent span if the parent span is not valid.
+func (t Tracer) recordsOperationSpanErrors(parent trace.Span) bool {
+	return t.parentSpanErrors != ParentSpanErrorsOnly || !parent.SpanContext().IsValid()
+}
@@ -0,0 +1,170 @@
+package otelgqlgen_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/aereal/otelgqlgen"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type errorsSummary struct {
+	Status     sdktrace.Status
+	Events     int
+	Attributes []attribute.KeyValue
+}
+
+func summarizeErrors(span tracetest.SpanStub) errorsSummary {
+	return errorsSummary{
+		Status:     span.Status,
+		Events:     len(span.Events),
+		Attributes: attrsWithPrefix(span.Attributes, "graphql.errors."),
+	}
+}
+
+func TestTracer_parentSpanErrors(t *testing.T) {
+	recorded := errorsSummary{
+		Status:     sdktrace.Status{Code: codes.Error, Description: "input: user forbidden\n"},
+		Events:     1,
+		Attributes: []attribute.KeyValue{},
+	}
+	notRecorded := errorsSummary{Attributes: []attribute.KeyValue{}}
+	type testCase struct {
+		name          string
+		mode          otelgqlgen.ParentSpanErrors
+		noParent      bool
+		wantOperation errorsSummary
+		wantParent    errorsSummary
+	}
+	testCases := []testCase{
+		{
+			name:          "record",
+			mode:          otelgqlgen.ParentSpanErrorsRecord,
+			wantOperation: recorded,
+			wantParent:    recorded,
+		},
+		{
+			name:          "only",
+			mode:          otelgqlgen.ParentSpanErrorsOnly,
+			wantOperation: notRecorded,
+			wantParent:    recorded,
+		},
+		{
+			name:          "only/no parent",
+			mode:          otelgqlgen.ParentSpanErrorsOnly,
+			noParent:      true,
+			wantOperation: recorded,
+		},
+		{
+			name:          "summary",
+			mode:          otelgqlgen.ParentSpanErrorsSummary,
+			wantOperation: recorded,
+			wantParent:    errorsSummary{Attributes: []attribute.KeyValue{attribute.Int("graphql.errors.count", 1)}},
+		},
+		{
+			name:          "none",
+			mode:          otelgqlgen.ParentSpanErrorsNone,
+			wantOperation: recorded,
+			wantParent:    notRecorded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, otelgqlgen.WithParentSpanErrors(tc.mode))
+			testTracer := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test")
+			var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reqCtx, span := testTracer.Start(r.Context(), "http_handler")
+				defer span.End()
+				gqlsrv.ServeHTTP(w, r.WithContext(reqCtx))
+			})
+			if tc.noParent {
+				h = gqlsrv
+			}
+			_ = doRequest(ctx, t, h, &graphql.RawParams{Query: `{user(name: "forbidden") {name}}`})
+			spans := exporter.GetSpans()
+			opts := []cmp.Option{cmp.Transformer("attribute.KeyValue", transformKeyValue)}
+			if diff := cmp.Diff(tc.wantOperation, summarizeErrors(findSpan(t, spans, "query")), opts...); diff != "" {
+				t.Errorf("operation span: -want, +got:\n%s", diff)
+			}
+			if tc.noParent {
+				return
+			}
+			if diff := cmp.Diff(tc.wantParent, summarizeErrors(findSpan(t, spans, "http_handler")), opts...); diff != "" {
+				t.Errorf("parent span: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTracer_parentSpanErrors_subscription(t *testing.T) {
+	type testCase struct {
+		name             string
+		mode             otelgqlgen.ParentSpanErrors
+		wantSubscription errorsSummary
+		wantParent       errorsSummary
+	}
+	recorded := errorsSummary{
+		Status:     sdktrace.Status{Code: codes.Error, Description: "input: name invalid name\n"},
+		Events:     1,
+		Attributes: []attribute.KeyValue{},
+	}
+	notRecorded := errorsSummary{Attributes: []attribute.KeyValue{}}
+	testCases := []testCase{
+		{
+			name:             "record",
+			mode:             otelgqlgen.ParentSpanErrorsRecord,
+			wantSubscription: recorded,
+			wantParent:       recorded,
+		},
+		{
+			name:             "only",
+			mode:             otelgqlgen.ParentSpanErrorsOnly,
+			wantSubscription: notRecorded,
+			wantParent:       recorded,
+		},
+		{
+			name:             "summary",
+			mode:             otelgqlgen.ParentSpanErrorsSummary,
+			wantSubscription: recorded,
+			wantParent:       errorsSummary{Attributes: []attribute.KeyValue{attribute.Int("graphql.errors.count", 1)}},
+		},
+		{
+			name:             "none",
+			mode:             otelgqlgen.ParentSpanErrorsNone,
+			wantSubscription: recorded,
+			wantParent:       notRecorded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServerWithTransport(t, transport.SSE{},
+				otelgqlgen.WithSubscriptionMode(otelgqlgen.SubscriptionChildEvents),
+				otelgqlgen.WithParentSpanErrors(tc.mode))
+			testTracer := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test")
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reqCtx, span := testTracer.Start(r.Context(), "http_handler")
+				defer span.End()
+				gqlsrv.ServeHTTP(w, r.WithContext(reqCtx))
+			})
+			_ = doRequestWithHeader(ctx, t, h,
+				&graphql.RawParams{Query: `subscription {userUpdated(names: ["a", "invalid"]) {name}}`},
+				http.Header{"Accept": []string{"text/event-stream"}})
+			spans := exporter.GetSpans()
+			opts := []cmp.Option{cmp.Transformer("attribute.KeyValue", transformKeyValue)}
+			if diff := cmp.Diff(tc.wantSubscription, summarizeErrors(findSpan(t, spans, "subscription")), opts...); diff != "" {
+				t.Errorf("subscription span: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantParent, summarizeErrors(findSpan(t, spans, "http_handler")), opts...); diff != "" {
+				t.Errorf("parent span: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
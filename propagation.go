@@ -0,0 +1,37 @@
+package otelgqlgen
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// extractTraceContext returns the context that has the span context propagated through the request extensions or the websocket init payload.
+//
+// The request extensions take precedence over the init payload.
+// The context is returned as is if neither of them has the valid span context.
+func (t Tracer) extractTraceContext(ctx context.Context) context.Context {
+	if !t.propagateFromExtensions {
+		return ctx
+	}
+	before := trace.SpanContextFromContext(ctx)
+	for _, src := range []map[string]any{graphql.GetOperationContext(ctx).Extensions, transport.GetInitPayload(ctx)} {
+		carrier := propagation.MapCarrier{}
+		for _, field := range t.propagator.Fields() {
+			if v, ok := src[field].(string); ok {
+				carrier[field] = v
+			}
+		}
+		if len(carrier) == 0 {
+			continue
+		}
+		extracted := t.propagator.Extract(ctx, carrier)
+		if sc := trace.SpanContextFromContext(extracted); sc.IsValid() && !sc.Equal(before) {
+			return extracted
+		}
+	}
+	return ctx
+}
@@ -0,0 +1,134 @@
+package otelgqlgen_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/aereal/otelgqlgen"
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer_extractTraceContextFromExtensions(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	type testCase struct {
+		name       string
+		enabled    bool
+		extensions map[string]any
+		wantParent trace.SpanContext
+	}
+	testCases := []testCase{
+		{
+			name:       "enabled",
+			enabled:    true,
+			extensions: map[string]any{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			wantParent: trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled, Remote: true}),
+		},
+		{
+			name:       "disabled",
+			extensions: map[string]any{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		},
+		{
+			name:       "invalid traceparent",
+			enabled:    true,
+			extensions: map[string]any{"traceparent": "invalid"},
+		},
+		{
+			name:       "no traceparent",
+			enabled:    true,
+			extensions: map[string]any{"persistedQuery": map[string]any{"version": 1}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t,
+				otelgqlgen.WithPropagators(propagation.TraceContext{}),
+				otelgqlgen.ExtractTraceContextFromExtensions(tc.enabled))
+			_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{Query: `{user(name: "aereal") {name}}`, Extensions: tc.extensions})
+			opSpan := findSpan(t, exporter.GetSpans(), "query")
+			if !opSpan.Parent.Equal(tc.wantParent) {
+				t.Errorf("parent: want=%#v got=%#v", tc.wantParent, opSpan.Parent)
+			}
+			if tc.wantParent.IsValid() && opSpan.SpanContext.TraceID() != tc.wantParent.TraceID() {
+				t.Errorf("trace ID: want=%s got=%s", tc.wantParent.TraceID(), opSpan.SpanContext.TraceID())
+			}
+		})
+	}
+}
+
+func TestTracer_extractTraceContextFromInitPayload(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	otherTraceID, _ := trace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+	otherSpanID, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+	type testCase struct {
+		name        string
+		initPayload map[string]any
+		extensions  map[string]any
+		wantParent  trace.SpanContext
+	}
+	testCases := []testCase{
+		{
+			name:        "init payload",
+			initPayload: map[string]any{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			wantParent:  trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled, Remote: true}),
+		},
+		{
+			name:        "extensions take precedence",
+			initPayload: map[string]any{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			extensions:  map[string]any{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+			wantParent:  trace.NewSpanContext(trace.SpanContextConfig{TraceID: otherTraceID, SpanID: otherSpanID, TraceFlags: trace.FlagsSampled, Remote: true}),
+		},
+		{
+			name:        "invalid init payload",
+			initPayload: map[string]any{"traceparent": "invalid"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServerWithTransport(t, transport.Websocket{},
+				otelgqlgen.WithPropagators(propagation.TraceContext{}),
+				otelgqlgen.ExtractTraceContextFromExtensions(true))
+			srv := httptest.NewServer(gqlsrv)
+			defer srv.Close()
+			dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+			conn, resp, err := dialer.DialContext(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+			if err != nil {
+				t.Fatalf("websocket.Dialer.DialContext: %+v", err)
+			}
+			defer conn.Close()
+			defer resp.Body.Close()
+			messages := []map[string]any{
+				{"type": "connection_init", "payload": tc.initPayload},
+				{"type": "subscribe", "id": "1", "payload": map[string]any{"query": `{user(name: "aereal") {name}}`, "extensions": tc.extensions}},
+			}
+			for _, msg := range messages {
+				if err := conn.WriteJSON(msg); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for {
+				var msg struct {
+					Type string `json:"type"`
+				}
+				if err := conn.ReadJSON(&msg); err != nil {
+					t.Fatal(err)
+				}
+				if msg.Type == "complete" {
+					break
+				}
+			}
+			opSpan := findSpan(t, exporter.GetSpans(), "query")
+			if !opSpan.Parent.Equal(tc.wantParent) {
+				t.Errorf("parent: want=%#v got=%#v", tc.wantParent, opSpan.Parent)
+			}
+		})
+	}
+}
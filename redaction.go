@@ -0,0 +1,100 @@
+package otelgqlgen
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
+const redactedMask = "[REDACTED]"
+
+// Redaction tells how Tracer records a value that may be sensitive.
+type Redaction int
+
+const (
+	// RedactionNone records the value as is.
+	RedactionNone Redaction = iota
+	// RedactionMask replaces the value with a fixed mask.
+	RedactionMask
+	// RedactionHash replaces the value with the hex-encoded HMAC-SHA256 digest of it keyed by WithRedactionHashKey.
+	//
+	// Without the key, the digest is the plain SHA-256 digest that is NOT a privacy control:
+	// the values with the small space such as passwords, tokens, emails and phone numbers are revealed by the dictionary attacks.
+	// It only helps to correlate the same values across the spans.
+	RedactionHash
+	// RedactionDrop does not record the value.
+	RedactionDrop
+)
+
+func (r Redaction) apply(val any, hashKey []byte) (any, bool) {
+	switch r {
+	case RedactionNone:
+		return val, true
+	case RedactionMask:
+		return redactedMask, true
+	case RedactionHash:
+		if len(hashKey) == 0 {
+			sum := sha256.Sum256(fmt.Appendf(nil, "%+v", val))
+			return hex.EncodeToString(sum[:]), true
+		}
+		mac := hmac.New(sha256.New, hashKey)
+		_, _ = fmt.Fprintf(mac, "%+v", val)
+		return hex.EncodeToString(mac.Sum(nil)), true
+	case RedactionDrop:
+		return nil, false
+	default:
+		return val, true
+	}
+}
+
+// stricter returns the redaction that records less of the value than the other.
+func (r Redaction) stricter(other Redaction) Redaction {
+	if redactionStrictness(other) > redactionStrictness(r) {
+		return other
+	}
+	return r
+}
+
+func redactionStrictness(r Redaction) int {
+	switch r {
+	case RedactionNone:
+		return 0
+	case RedactionHash:
+		return 1
+	case RedactionMask:
+		return 2
+	case RedactionDrop:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// RedactionPolicy decides how the value named name should be recorded.
+//
+// The context holds the [github.com/99designs/gqlgen/graphql.OperationContext] of the current operation,
+// so the policy can redact or drop the values per operation.
+type RedactionPolicy func(ctx context.Context, name string) Redaction
+
+// AllowNames returns a RedactionPolicy that records the values of the given names as is and redacts others with r.
+func AllowNames(r Redaction, names ...string) RedactionPolicy {
+	return func(_ context.Context, name string) Redaction {
+		if slices.Contains(names, name) {
+			return RedactionNone
+		}
+		return r
+	}
+}
+
+// DenyNames returns a RedactionPolicy that redacts the values of the given names with r and records others as is.
+func DenyNames(r Redaction, names ...string) RedactionPolicy {
+	return func(_ context.Context, name string) Redaction {
+		if slices.Contains(names, name) {
+			return r
+		}
+		return RedactionNone
+	}
+}
@@ -0,0 +1,87 @@
+package otelgqlgen_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestTracer_variableRedaction(t *testing.T) {
+	type testCase struct {
+		name    string
+		options []otelgqlgen.Option
+		want    []attribute.KeyValue
+	}
+	testCases := []testCase{
+		{
+			name: "no policy",
+			want: []attribute.KeyValue{
+				attribute.String("graphql.operation.variables.name", "aereal"),
+				attribute.String("graphql.operation.variables.num", "1"),
+			},
+		},
+		{
+			name:    "deny/mask",
+			options: []otelgqlgen.Option{otelgqlgen.WithVariableRedaction(otelgqlgen.DenyNames(otelgqlgen.RedactionMask, "name"))},
+			want: []attribute.KeyValue{
+				attribute.String("graphql.operation.variables.name", "[REDACTED]"),
+				attribute.String("graphql.operation.variables.num", "1"),
+			},
+		},
+		{
+			name:    "deny/hash",
+			options: []otelgqlgen.Option{otelgqlgen.WithVariableRedaction(otelgqlgen.DenyNames(otelgqlgen.RedactionHash, "name"))},
+			want: []attribute.KeyValue{
+				attribute.String("graphql.operation.variables.name", "e0d9984c839978e2feb779c8d44239b91e034d45260ceafc2f8b7ce879d18abc"),
+				attribute.String("graphql.operation.variables.num", "1"),
+			},
+		},
+		{
+			name: "deny/hash with key",
+			options: []otelgqlgen.Option{
+				otelgqlgen.WithVariableRedaction(otelgqlgen.DenyNames(otelgqlgen.RedactionHash, "name")),
+				otelgqlgen.WithRedactionHashKey([]byte("secret")),
+			},
+			want: []attribute.KeyValue{
+				attribute.String("graphql.operation.variables.name", "c0c375c1b7bc023655695a8bf72adb0b6a550f99b2054c5b4f36754a210ab839"),
+				attribute.String("graphql.operation.variables.num", "1"),
+			},
+		},
+		{
+			name:    "allow",
+			options: []otelgqlgen.Option{otelgqlgen.WithVariableRedaction(otelgqlgen.AllowNames(otelgqlgen.RedactionDrop, "num"))},
+			want: []attribute.KeyValue{
+				attribute.String("graphql.operation.variables.num", "1"),
+			},
+		},
+		{
+			name: "drop per operation",
+			options: []otelgqlgen.Option{otelgqlgen.WithVariableRedaction(func(ctx context.Context, _ string) otelgqlgen.Redaction {
+				if graphql.GetOperationContext(ctx).OperationName == "signIn" {
+					return otelgqlgen.RedactionDrop
+				}
+				return otelgqlgen.RedactionNone
+			})},
+			want: []attribute.KeyValue{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, tc.options...)
+			_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{
+				Query:         `query signIn($name: String!, $num: Int) {user(name: $name) {name} root(num: $num)}`,
+				OperationName: "signIn",
+				Variables:     map[string]any{"name": "aereal", "num": 1},
+			})
+			got := attrsWithPrefix(findSpan(t, exporter.GetSpans(), "signIn").Attributes, "graphql.operation.variables.")
+			if diff := cmp.Diff(tc.want, got, cmp.Transformer("attribute.KeyValue", transformKeyValue), sortAttrs); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
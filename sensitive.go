@@ -0,0 +1,183 @@
+package otelgqlgen
+
+import (
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// sensitiveSchema holds the schema coordinates annotated with the sensitive directive.
+//
+// The coordinates are collected by Tracer.Validate, so a nil or not yet collected sensitiveSchema treats nothing as sensitive.
+type sensitiveSchema struct {
+	schema      *ast.Schema
+	arguments   map[string]bool
+	inputFields map[string]bool
+	directive   string
+}
+
+func newSensitiveSchema(directive string) *sensitiveSchema {
+	return &sensitiveSchema{
+		directive:   directive,
+		arguments:   map[string]bool{},
+		inputFields: map[string]bool{},
+	}
+}
+
+func argumentCoordinate(typeName, fieldName, argName string) string {
+	return typeName + "." + fieldName + "(" + argName + ":)"
+}
+
+func inputFieldCoordinate(typeName, fieldName string) string {
+	return typeName + "." + fieldName
+}
+
+// collect collects the schema coordinates annotated with the directive.
+//
+// It returns an error if the directive is not declared in the schema, because the misspelled directive name would mask nothing.
+func (s *sensitiveSchema) collect(schema *ast.Schema) error {
+	if s == nil || schema == nil {
+		return nil
+	}
+	if schema.Directives[s.directive] == nil {
+		return fmt.Errorf("the sensitive directive @%s is not declared in the schema", s.directive)
+	}
+	s.schema = schema
+	for _, def := range schema.Types {
+		for _, field := range def.Fields {
+			if def.Kind == ast.InputObject {
+				if field.Directives.ForName(s.directive) != nil {
+					s.inputFields[inputFieldCoordinate(def.Name, field.Name)] = true
+				}
+				continue
+			}
+			for _, arg := range field.Arguments {
+				if arg.Directives.ForName(s.directive) != nil {
+					s.arguments[argumentCoordinate(def.Name, field.Name, arg.Name)] = true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *sensitiveSchema) isArgument(typeName, fieldName, argName string) bool {
+	return s != nil && s.arguments[argumentCoordinate(typeName, fieldName, argName)]
+}
+
+func (s *sensitiveSchema) isInputField(typeName, fieldName string) bool {
+	return s != nil && s.inputFields[inputFieldCoordinate(typeName, fieldName)]
+}
+
+func (s *sensitiveSchema) inputFieldType(typeName, fieldName string) string {
+	if s == nil || s.schema == nil {
+		return ""
+	}
+	def := s.schema.Types[typeName]
+	if def == nil {
+		return ""
+	}
+	field := def.Fields.ForName(fieldName)
+	if field == nil {
+		return ""
+	}
+	return field.Type.Name()
+}
+
+// redactVariables returns the variables of the operation that the sensitive values are masked.
+//
+// The variable passed to the sensitive arguments or input fields is entirely masked,
+// and the sensitive fields of the input objects in the other variables are masked.
+func (s *sensitiveSchema) redactVariables(opCtx *graphql.OperationContext) map[string]any {
+	if s == nil || s.schema == nil || opCtx.Operation == nil || len(opCtx.Variables) == 0 {
+		return opCtx.Variables
+	}
+	sensitiveVars := map[string]bool{}
+	s.walkSelectionSet(opCtx.Operation.SelectionSet, sensitiveVars, map[string]bool{})
+	vars := make(map[string]any, len(opCtx.Variables))
+	for name, val := range opCtx.Variables {
+		if sensitiveVars[name] {
+			vars[name] = redactedMask
+			continue
+		}
+		if def := opCtx.Operation.VariableDefinitions.ForName(name); def != nil {
+			val = s.maskValue(val, def.Type.Name())
+		}
+		vars[name] = val
+	}
+	return vars
+}
+
+func (s *sensitiveSchema) walkSelectionSet(set ast.SelectionSet, sensitiveVars, visitedFragments map[string]bool) {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if sel.Definition != nil && sel.ObjectDefinition != nil {
+				for _, arg := range sel.Arguments {
+					argDef := sel.Definition.Arguments.ForName(arg.Name)
+					if argDef == nil {
+						continue
+					}
+					sensitive := s.isArgument(sel.ObjectDefinition.Name, sel.Name, arg.Name)
+					s.walkValue(arg.Value, argDef.Type.Name(), sensitive, sensitiveVars)
+				}
+			}
+			s.walkSelectionSet(sel.SelectionSet, sensitiveVars, visitedFragments)
+		case *ast.InlineFragment:
+			s.walkSelectionSet(sel.SelectionSet, sensitiveVars, visitedFragments)
+		case *ast.FragmentSpread:
+			if sel.Definition == nil || visitedFragments[sel.Name] {
+				continue
+			}
+			visitedFragments[sel.Name] = true
+			s.walkSelectionSet(sel.Definition.SelectionSet, sensitiveVars, visitedFragments)
+		}
+	}
+}
+
+func (s *sensitiveSchema) walkValue(val *ast.Value, typeName string, sensitive bool, sensitiveVars map[string]bool) {
+	if val == nil {
+		return
+	}
+	switch val.Kind {
+	case ast.Variable:
+		if sensitive {
+			sensitiveVars[val.Raw] = true
+		}
+	case ast.ObjectValue:
+		for _, child := range val.Children {
+			childSensitive := sensitive || s.isInputField(typeName, child.Name)
+			s.walkValue(child.Value, s.inputFieldType(typeName, child.Name), childSensitive, sensitiveVars)
+		}
+	case ast.ListValue:
+		for _, child := range val.Children {
+			s.walkValue(child.Value, typeName, sensitive, sensitiveVars)
+		}
+	case ast.IntValue, ast.FloatValue, ast.StringValue, ast.BlockValue, ast.BooleanValue, ast.NullValue, ast.EnumValue:
+	}
+}
+
+// maskValue returns the value that the sensitive fields of the input object typed typeName are masked.
+func (s *sensitiveSchema) maskValue(val any, typeName string) any {
+	switch val := val.(type) {
+	case map[string]any:
+		masked := make(map[string]any, len(val))
+		for k, v := range val {
+			if s.isInputField(typeName, k) {
+				masked[k] = redactedMask
+				continue
+			}
+			masked[k] = s.maskValue(v, s.inputFieldType(typeName, k))
+		}
+		return masked
+	case []any:
+		masked := make([]any, len(val))
+		for i, v := range val {
+			masked[i] = s.maskValue(v, typeName)
+		}
+		return masked
+	default:
+		return val
+	}
+}
@@ -0,0 +1,127 @@
+package otelgqlgen_test
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"github.com/aereal/otelgqlgen/internal/test/execschema"
+	"github.com/aereal/otelgqlgen/internal/test/resolvers"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestTracer_sensitiveDirective(t *testing.T) {
+	type testCase struct {
+		name      string
+		params    *graphql.RawParams
+		fieldSpan string
+		wantArgs  []attribute.KeyValue
+		wantVars  []attribute.KeyValue
+	}
+	testCases := []testCase{
+		{
+			name:      "literal argument",
+			params:    &graphql.RawParams{Query: `mutation {signIn(name: "aereal", password: "secret")}`},
+			fieldSpan: "Mutation/signIn",
+			wantArgs: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.name", `"aereal"`),
+				attribute.String("graphql.resolver.args.password", "[REDACTED]"),
+			},
+			wantVars: []attribute.KeyValue{},
+		},
+		{
+			name: "argument passed through variable",
+			params: &graphql.RawParams{
+				Query:     `mutation($name: String!, $password: String!) {signIn(name: $name, password: $password)}`,
+				Variables: map[string]any{"name": "aereal", "password": "secret"},
+			},
+			fieldSpan: "Mutation/signIn",
+			wantArgs: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.name", "$name"),
+				attribute.String("graphql.resolver.args.password", "[REDACTED]"),
+			},
+			wantVars: []attribute.KeyValue{
+				attribute.String("graphql.operation.variables.name", "aereal"),
+				attribute.String("graphql.operation.variables.password", "[REDACTED]"),
+			},
+		},
+		{
+			name:      "literal input field",
+			params:    &graphql.RawParams{Query: `mutation {signInWith(credentials: {name: "aereal", password: "secret"})}`},
+			fieldSpan: "Mutation/signInWith",
+			wantArgs: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.credentials.name", `"aereal"`),
+				attribute.String("graphql.resolver.args.credentials.password", "[REDACTED]"),
+			},
+			wantVars: []attribute.KeyValue{},
+		},
+		{
+			name: "input field passed through variable",
+			params: &graphql.RawParams{
+				Query:     `mutation($password: String!) {signInWith(credentials: {name: "aereal", password: $password})}`,
+				Variables: map[string]any{"password": "secret"},
+			},
+			fieldSpan: "Mutation/signInWith",
+			wantArgs: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.credentials.name", `"aereal"`),
+				attribute.String("graphql.resolver.args.credentials.password", "[REDACTED]"),
+			},
+			wantVars: []attribute.KeyValue{
+				attribute.String("graphql.operation.variables.password", "[REDACTED]"),
+			},
+		},
+		{
+			name: "input object variable",
+			params: &graphql.RawParams{
+				Query:     `mutation($credentials: Credentials!) {signInWith(credentials: $credentials)}`,
+				Variables: map[string]any{"credentials": map[string]any{"name": "aereal", "password": "secret"}},
+			},
+			fieldSpan: "Mutation/signInWith",
+			wantArgs: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.credentials", "$credentials"),
+			},
+			wantVars: []attribute.KeyValue{
+				attribute.String("graphql.operation.variables.credentials", "map[name:aereal password:[REDACTED]]"),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, otelgqlgen.WithSensitiveDirective("sensitive"))
+			_ = doRequest(ctx, t, gqlsrv, tc.params)
+			spans := exporter.GetSpans()
+			opts := []cmp.Option{cmp.Transformer("attribute.KeyValue", transformKeyValue), sortAttrs}
+			gotArgs := attrsWithPrefix(findSpan(t, spans, tc.fieldSpan).Attributes, "graphql.resolver.args.")
+			if diff := cmp.Diff(tc.wantArgs, gotArgs, opts...); diff != "" {
+				t.Errorf("args: -want, +got:\n%s", diff)
+			}
+			gotVars := attrsWithPrefix(findSpan(t, spans, "mutation").Attributes, "graphql.operation.variables.")
+			if diff := cmp.Diff(tc.wantVars, gotVars, opts...); diff != "" {
+				t.Errorf("variables: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTracer_Validate_sensitiveDirective(t *testing.T) {
+	type testCase struct {
+		name      string
+		directive string
+		wantErr   bool
+	}
+	testCases := []testCase{
+		{name: "declared", directive: "sensitive"},
+		{name: "not declared", directive: "sensitiv", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tracer := otelgqlgen.New(otelgqlgen.WithSensitiveDirective(tc.directive))
+			err := tracer.Validate(execschema.NewExecutableSchema(execschema.Config{Resolvers: &resolvers.Resolver{}}))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("wantErr=%v got=%v", tc.wantErr, err)
+			}
+		})
+	}
+}
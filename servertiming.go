@@ -0,0 +1,133 @@
+package otelgqlgen
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+const serverTimingHeader = "Server-Timing"
+
+type serverTimingKey struct{}
+
+// serverTiming holds the timings of the operation phases until the response header is written.
+type serverTiming struct {
+	metrics []serverTimingMetric
+	mu      sync.Mutex
+}
+
+type serverTimingMetric struct {
+	name string
+	dur  time.Duration
+}
+
+func serverTimingFromContext(ctx context.Context) *serverTiming {
+	st, _ := ctx.Value(serverTimingKey{}).(*serverTiming)
+	return st
+}
+
+func (st *serverTiming) add(name string, dur time.Duration) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.metrics = append(st.metrics, serverTimingMetric{name: name, dur: dur})
+}
+
+// String returns the value of Server-Timing header (e.g. "parse;dur=0.3, validate;dur=1.1, exec;dur=42").
+func (st *serverTiming) String() string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	vals := make([]string, len(st.metrics))
+	for i, m := range st.metrics {
+		ms := float64(m.dur.Microseconds()) / 1000
+		vals[i] = m.name + ";dur=" + strconv.FormatFloat(ms, 'f', -1, 64)
+	}
+	return strings.Join(vals, ", ")
+}
+
+// ServerTimingMiddleware returns the HTTP middleware that writes the timings of the operation phases recorded by Tracer into Server-Timing response header.
+//
+// Tracer records the timings only if ExposeServerTiming is enabled.
+// The header is written only if the timings are recorded before the response header is sent, so the subscriptions and the incremental deliveries do not have it.
+func ServerTimingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st := &serverTiming{}
+		ctx := context.WithValue(r.Context(), serverTimingKey{}, st)
+		next.ServeHTTP(&serverTimingWriter{ResponseWriter: w, timing: st}, r.WithContext(ctx))
+	})
+}
+
+type serverTimingWriter struct {
+	http.ResponseWriter
+	timing      *serverTiming
+	wroteHeader bool
+}
+
+var (
+	_ http.Flusher  = (*serverTimingWriter)(nil)
+	_ http.Hijacker = (*serverTimingWriter)(nil)
+)
+
+func (w *serverTimingWriter) writeTiming() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if v := w.timing.String(); v != "" {
+		w.Header().Set(serverTimingHeader, v)
+	}
+}
+
+func (w *serverTimingWriter) WriteHeader(statusCode int) {
+	w.writeTiming()
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *serverTimingWriter) Write(b []byte) (int, error) {
+	w.writeTiming()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *serverTimingWriter) Flush() {
+	w.writeTiming()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the underlying connection so that the websocket transports can upgrade the connection.
+func (w *serverTimingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T does not implement http.Hijacker", w.ResponseWriter)
+	}
+	w.wroteHeader = true
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (w *serverTimingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// recordServerTiming records the timings of the operation phases into the context prepared by ServerTimingMiddleware.
+func (t Tracer) recordServerTiming(ctx context.Context, execStartedAt time.Time) {
+	if !t.exposeServerTiming {
+		return
+	}
+	st := serverTimingFromContext(ctx)
+	if st == nil {
+		return
+	}
+	stats := graphql.GetOperationContext(ctx).Stats
+	st.add("parse", stats.Parsing.End.Sub(stats.Parsing.Start))
+	st.add("validate", stats.Validation.End.Sub(stats.Validation.Start))
+	st.add("exec", time.Since(execStartedAt))
+}
@@ -0,0 +1,75 @@
+package otelgqlgen_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/aereal/otelgqlgen"
+	"github.com/gorilla/websocket"
+)
+
+func TestServerTimingMiddleware(t *testing.T) {
+	type testCase struct {
+		name    string
+		options []otelgqlgen.Option
+		want    *regexp.Regexp
+	}
+	testCases := []testCase{
+		{
+			name:    "enabled",
+			options: []otelgqlgen.Option{otelgqlgen.ExposeServerTiming(true)},
+			want:    regexp.MustCompile(`\Aparse;dur=[0-9.]+, validate;dur=[0-9.]+, exec;dur=[0-9.]+\z`),
+		},
+		{
+			name: "disabled",
+			want: regexp.MustCompile(`\A\z`),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, _ := newTestServer(t, tc.options...)
+			req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/", strings.NewReader(`{"query":"{user(name: \"aereal\") {name}}"}`))
+			req.Header.Set("content-type", "application/json")
+			rec := httptest.NewRecorder()
+			otelgqlgen.ServerTimingMiddleware(gqlsrv).ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status code: %d", rec.Code)
+			}
+			if got := rec.Header().Get("Server-Timing"); !tc.want.MatchString(got) {
+				t.Errorf("Server-Timing: want=%s got=%q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServerTimingMiddleware_websocket(t *testing.T) {
+	ctx := testContext(t)
+	gqlsrv, _ := newTestServer(t, otelgqlgen.ExposeServerTiming(true))
+	gqlsrv.AddTransport(transport.Websocket{})
+	srv := httptest.NewServer(otelgqlgen.ServerTimingMiddleware(gqlsrv))
+	defer srv.Close()
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+	conn, resp, err := dialer.DialContext(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("websocket.Dialer.DialContext: %+v", err)
+	}
+	defer conn.Close()
+	defer resp.Body.Close()
+	if err := conn.WriteJSON(map[string]any{"type": "connection_init"}); err != nil {
+		t.Fatal(err)
+	}
+	var msg struct {
+		Type string `json:"type"`
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != "connection_ack" {
+		t.Errorf("message type: want=connection_ack got=%s", msg.Type)
+	}
+}
@@ -0,0 +1,81 @@
+package otelgqlgen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type fastFieldsKey struct{}
+
+// fastFields summarizes the fields that are resolved faster than the threshold during an operation.
+type fastFields struct {
+	count int
+	total time.Duration
+	mu    sync.Mutex
+}
+
+func withFastFields(ctx context.Context) (context.Context, *fastFields) {
+	fast := &fastFields{}
+	return context.WithValue(ctx, fastFieldsKey{}, fast), fast
+}
+
+func fastFieldsFromContext(ctx context.Context) *fastFields {
+	fast, _ := ctx.Value(fastFieldsKey{}).(*fastFields)
+	return fast
+}
+
+func (f *fastFields) add(d time.Duration) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.count++
+	f.total += d
+}
+
+func (f *fastFields) attributes() []attribute.KeyValue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return []attribute.KeyValue{
+		keyFastFieldsCount.Int(f.count),
+		keyFastFieldsDurationTotal.Float64(f.total.Seconds()),
+	}
+}
+
+// interceptSlowField resolves the field and creates the span retroactively only if the resolver is slow or failed.
+func (t Tracer) interceptSlowField(ctx context.Context, fieldCtx *graphql.FieldContext, depth int, next graphql.Resolver) (any, error) {
+	startedAt := time.Now()
+	resp, err := next(ctx)
+	endedAt := time.Now()
+	t.recordResolverMetrics(ctx, fieldCtx, startedAt)
+	errs := graphql.GetFieldErrors(ctx, fieldCtx)
+	if err != nil {
+		errs = append(errs, gqlerror.WrapPath(fieldCtx.Path(), err))
+	}
+	if elapsed := endedAt.Sub(startedAt); elapsed < t.slowFieldThreshold && len(errs) == 0 {
+		fastFieldsFromContext(ctx).add(elapsed)
+		return resp, err
+	}
+	_, span := t.tracer.Start(ctx, t.fieldSpanName(fieldCtx),
+		trace.WithTimestamp(startedAt),
+		trace.WithSpanKind(t.nestedSpanKind))
+	defer span.End(trace.WithTimestamp(endedAt))
+	if t.errorTraceExtension != nil {
+		fieldSpansFromContext(ctx).add(fieldCtx.Path().String(), span.SpanContext())
+	}
+	if !span.IsRecording() {
+		return resp, err
+	}
+	span.SetAttributes(t.attrsFieldSpan(ctx, fieldCtx, depth)...)
+	if len(errs) > 0 {
+		t.recordGQLErrors(span, errs)
+	}
+	return resp, err
+}
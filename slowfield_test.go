@@ -0,0 +1,90 @@
+package otelgqlgen_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aereal/otelgqlgen"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestTracer_slowFieldThreshold(t *testing.T) {
+	type testCase struct {
+		name           string
+		threshold      time.Duration
+		userName       string
+		wantFieldSpans []string
+		wantErrorSpans []string
+		wantFastCount  int64
+	}
+	testCases := []testCase{
+		{
+			name:           "all fields are fast",
+			threshold:      time.Hour,
+			userName:       "aereal",
+			wantFieldSpans: []string{},
+			wantErrorSpans: []string{},
+			wantFastCount:  3,
+		},
+		{
+			name:           "failed fields",
+			threshold:      time.Hour,
+			userName:       "invalid",
+			wantFieldSpans: []string{"User/age", "User/name"},
+			wantErrorSpans: []string{"User/age", "User/name"},
+			wantFastCount:  1,
+		},
+		{
+			name:           "all fields are slow",
+			threshold:      time.Nanosecond,
+			userName:       "aereal",
+			wantFieldSpans: []string{"Query/user", "User/age", "User/name"},
+			wantErrorSpans: []string{},
+			wantFastCount:  0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, otelgqlgen.ShouldTraceCaptureTimings(false), otelgqlgen.WithSlowFieldThreshold(tc.threshold))
+			_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{
+				Query:     `query($name: String!) {user(name: $name) {name age}}`,
+				Variables: map[string]any{"name": tc.userName},
+			})
+			spans := exporter.GetSpans()
+			gotFieldSpans := make([]string, 0)
+			gotErrorSpans := make([]string, 0)
+			for _, span := range spans {
+				if span.Name == "query" {
+					continue
+				}
+				gotFieldSpans = append(gotFieldSpans, span.Name)
+				if span.Status.Code == codes.Error {
+					gotErrorSpans = append(gotErrorSpans, span.Name)
+				}
+				if span.EndTime.Before(span.StartTime) {
+					t.Errorf("span %s: end time (%s) must not be before start time (%s)", span.Name, span.EndTime, span.StartTime)
+				}
+			}
+			sortStrings := cmpopts.SortSlices(func(x, y string) bool { return x < y })
+			if diff := cmp.Diff(tc.wantFieldSpans, gotFieldSpans, sortStrings); diff != "" {
+				t.Errorf("field spans: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantErrorSpans, gotErrorSpans, sortStrings); diff != "" {
+				t.Errorf("error spans: -want, +got:\n%s", diff)
+			}
+			var gotFastCount int64
+			for _, attr := range findSpan(t, spans, "query").Attributes {
+				if attr.Key == "graphql.operation.fast_fields.count" {
+					gotFastCount = attr.Value.AsInt64()
+				}
+			}
+			if gotFastCount != tc.wantFastCount {
+				t.Errorf("fast fields count: want=%d got=%d", tc.wantFastCount, gotFastCount)
+			}
+		})
+	}
+}
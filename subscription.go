@@ -0,0 +1,214 @@
+package otelgqlgen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SubscriptionMode tells how Tracer traces the subscription operations.
+type SubscriptionMode int
+
+const (
+	// SubscriptionPerEvent creates an operation span per the event and no span for the subscription itself.
+	SubscriptionPerEvent SubscriptionMode = iota
+	// SubscriptionChildEvents creates a span for the subscription lifetime and the event spans as the children of it.
+	SubscriptionChildEvents
+	// SubscriptionLinkedEvents creates a span for the subscription lifetime and the event spans as the new root spans linked to it.
+	SubscriptionLinkedEvents
+)
+
+type subscriptionKey struct{}
+
+// subscription holds the span for the lifetime of a subscription operation.
+type subscription struct {
+	span        trace.Span
+	parent      trace.Span
+	lastEventAt time.Time
+	errors      gqlerror.List
+	events      int
+	mu          sync.Mutex
+	once        sync.Once
+}
+
+func withSubscription(ctx context.Context, sub *subscription) context.Context {
+	return context.WithValue(ctx, subscriptionKey{}, sub)
+}
+
+func subscriptionFromContext(ctx context.Context) *subscription {
+	sub, _ := ctx.Value(subscriptionKey{}).(*subscription)
+	return sub
+}
+
+// emit counts the event and returns the sequence number of it and the duration since the previous event or the start of the subscription.
+func (sub *subscription) emit(at time.Time) (int, time.Duration) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	sub.events++
+	interval := at.Sub(sub.lastEventAt)
+	sub.lastEventAt = at
+	return sub.events, interval
+}
+
+// record collects the errors of an event to propagate them onto the parent span when the subscription ends.
+func (sub *subscription) record(errs gqlerror.List) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	sub.errors = append(sub.errors, errs...)
+}
+
+func (sub *subscription) end(t Tracer) {
+	sub.once.Do(func() {
+		sub.mu.Lock()
+		defer sub.mu.Unlock()
+		t.recordParentSpanErrors(sub.parent, sub.errors)
+		sub.span.SetAttributes(keySubscriptionEvents.Int(sub.events))
+		sub.span.End()
+	})
+}
+
+// interceptSubscription starts the span for the lifetime of the subscription and ends it when the subscription completes.
+//
+// The span is also ended when the context of the operation is done, because the transports may stop requesting the events before the completion.
+func (t Tracer) interceptSubscription(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	parentSpan := trace.SpanFromContext(ctx)
+	ctx, span := t.startResponseSpan(ctx)
+	if span.IsRecording() {
+		if t.shouldTraceCaptureTimings {
+			t.captureOperationTimings(ctx)
+		}
+		span.SetAttributes(t.attrsOperation(ctx)...)
+	}
+	sub := &subscription{span: span, parent: parentSpan, lastEventAt: time.Now()}
+	end := func() { sub.end(t) }
+	stop := context.AfterFunc(ctx, end)
+	handler := next(withSubscription(ctx, sub))
+	return func(ctx context.Context) *graphql.Response {
+		resp := handler(ctx)
+		if resp == nil {
+			stop()
+			end()
+			return nil
+		}
+		if len(resp.Errors) > 0 {
+			sub.record(resp.Errors)
+			if t.recordsOperationSpanErrors(parentSpan) {
+				t.recordGQLErrors(span, resp.Errors)
+			}
+		}
+		return resp
+	}
+}
+
+type eventArrivalKey struct{}
+
+// eventArrival holds the time that an event of the subscription arrives.
+//
+// The response handler waits for the event before resolving the fields of it, so the event is considered to arrive
+// when the first field of it is resolved, or when the response handler returns if no field is intercepted.
+type eventArrival struct {
+	at time.Time
+	mu sync.Mutex
+}
+
+func withEventArrival(ctx context.Context, arrival *eventArrival) context.Context {
+	return context.WithValue(ctx, eventArrivalKey{}, arrival)
+}
+
+func eventArrivalFromContext(ctx context.Context) *eventArrival {
+	arrival, _ := ctx.Value(eventArrivalKey{}).(*eventArrival)
+	return arrival
+}
+
+// arrive records the time that the event arrives if it is not recorded yet, and returns it.
+func (arrival *eventArrival) arrive() time.Time {
+	arrival.mu.Lock()
+	defer arrival.mu.Unlock()
+	if arrival.at.IsZero() {
+		arrival.at = time.Now()
+	}
+	return arrival.at
+}
+
+type subscriptionEventKey struct{}
+
+// subscriptionEvent holds the span for an event of the subscription.
+//
+// The span is started lazily when the event arrives, because the transport calls the response handler until it returns nil
+// and the last call has no event to be traced.
+type subscriptionEvent struct {
+	sub     *subscription
+	arrival *eventArrival
+	span    trace.Span
+	mu      sync.Mutex
+}
+
+func subscriptionEventFromContext(ctx context.Context) *subscriptionEvent {
+	ev, _ := ctx.Value(subscriptionEventKey{}).(*subscriptionEvent)
+	return ev
+}
+
+// start starts the event span if it is not started yet, and returns the context that holds it.
+func (ev *subscriptionEvent) start(ctx context.Context, t Tracer) (context.Context, trace.Span) {
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	if ev.span == nil {
+		opts := []trace.SpanStartOption{trace.WithSpanKind(t.nestedSpanKind)}
+		if t.subscriptionMode == SubscriptionLinkedEvents {
+			opts = []trace.SpanStartOption{
+				trace.WithSpanKind(t.operationSpanKind),
+				trace.WithNewRoot(),
+				trace.WithLinks(trace.Link{SpanContext: ev.sub.span.SpanContext()}),
+			}
+		}
+		opts = append(opts, trace.WithTimestamp(ev.arrival.arrive()))
+		// the event span is the child of the subscription span regardless of the context of the caller (e.g. the field context).
+		parentCtx := trace.ContextWithSpan(ctx, ev.sub.span)
+		_, ev.span = t.tracer.Start(parentCtx, t.operationSpanName(graphql.GetOperationContext(ctx))+" event", opts...)
+	}
+	return trace.ContextWithSpan(ctx, ev.span), ev.span
+}
+
+func (ev *subscriptionEvent) started() bool {
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	return ev.span != nil
+}
+
+// interceptSubscriptionEvent traces an event of the subscription.
+//
+// The transport calls the response handler until it returns nil, so the span is not started for the last call that emits no event.
+func (t Tracer) interceptSubscriptionEvent(ctx context.Context, sub *subscription, next graphql.ResponseHandler) *graphql.Response {
+	arrival := &eventArrival{}
+	ev := &subscriptionEvent{sub: sub, arrival: arrival}
+	ctx, finish := t.withResponseState(context.WithValue(withEventArrival(ctx, arrival), subscriptionEventKey{}, ev))
+	resp := next(ctx)
+	if resp == nil && !ev.started() {
+		return nil
+	}
+	startedAt := arrival.arrive()
+	ctx, span := ev.start(ctx, t)
+	defer span.End()
+	finish(span)
+	if resp == nil {
+		return nil
+	}
+	if len(resp.Errors) > 0 {
+		if t.recordsOperationSpanErrors(sub.parent) {
+			t.recordGQLErrors(span, resp.Errors)
+		}
+		t.setErrorTraceExtensions(ctx, resp.Errors, span.SpanContext())
+	}
+	t.setResponseTraceExtension(resp, span.SpanContext())
+	seq, interval := sub.emit(time.Now())
+	span.SetAttributes(
+		keySubscriptionEventSequence.Int(seq),
+		keySubscriptionEventInterval.Float64(interval.Seconds()),
+	)
+	t.recordOperationMetrics(ctx, startedAt, resp)
+	return resp
+}
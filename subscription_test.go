@@ -0,0 +1,208 @@
+package otelgqlgen_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/aereal/otelgqlgen"
+	"github.com/aereal/otelgqlgen/internal/test/execschema"
+	"github.com/aereal/otelgqlgen/internal/test/model"
+	"github.com/aereal/otelgqlgen/internal/test/resolvers"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spanCounter counts the started and the ended spans to find the spans that are never ended.
+type spanCounter struct {
+	started atomic.Int64
+	ended   atomic.Int64
+}
+
+var _ sdktrace.SpanProcessor = (*spanCounter)(nil)
+
+func (c *spanCounter) OnStart(context.Context, sdktrace.ReadWriteSpan) { c.started.Add(1) }
+func (c *spanCounter) OnEnd(sdktrace.ReadOnlySpan)                     { c.ended.Add(1) }
+func (c *spanCounter) Shutdown(context.Context) error                  { return nil }
+func (c *spanCounter) ForceFlush(context.Context) error                { return nil }
+
+type subscriptionSpan struct {
+	Name     string
+	Parent   string
+	Linked   string
+	Sequence int64
+	Events   int64
+	Status   codes.Code
+}
+
+func TestTracer_subscriptionMode(t *testing.T) {
+	type testCase struct {
+		name    string
+		options []otelgqlgen.Option
+		want    []subscriptionSpan
+	}
+	testCases := []testCase{
+		{
+			name: "per event",
+			want: []subscriptionSpan{
+				{Name: "Subscription/userUpdated"},
+				{Name: "subscription"},
+				{Name: "subscription", Status: codes.Error},
+				{Name: "subscription"},
+			},
+		},
+		{
+			name:    "child events",
+			options: []otelgqlgen.Option{otelgqlgen.WithSubscriptionMode(otelgqlgen.SubscriptionChildEvents)},
+			want: []subscriptionSpan{
+				{Name: "Subscription/userUpdated", Parent: "subscription"},
+				{Name: "subscription event", Parent: "subscription", Sequence: 1},
+				{Name: "subscription event", Parent: "subscription", Sequence: 2, Status: codes.Error},
+				{Name: "subscription", Events: 2, Status: codes.Error},
+			},
+		},
+		{
+			name:    "linked events",
+			options: []otelgqlgen.Option{otelgqlgen.WithSubscriptionMode(otelgqlgen.SubscriptionLinkedEvents)},
+			want: []subscriptionSpan{
+				{Name: "Subscription/userUpdated", Parent: "subscription"},
+				{Name: "subscription event", Linked: "subscription", Sequence: 1},
+				{Name: "subscription event", Linked: "subscription", Sequence: 2, Status: codes.Error},
+				{Name: "subscription", Events: 2, Status: codes.Error},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			exporter := tracetest.NewInMemoryExporter()
+			counter := &spanCounter{}
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter), sdktrace.WithSpanProcessor(counter))
+			gqlsrv := handler.New(execschema.NewExecutableSchema(execschema.Config{Resolvers: &resolvers.Resolver{}}))
+			gqlsrv.AddTransport(transport.SSE{})
+			options := append([]otelgqlgen.Option{otelgqlgen.WithTracerProvider(tp), otelgqlgen.ShouldTraceCaptureTimings(false), otelgqlgen.WithFieldFilter(otelgqlgen.IncludeFields("Subscription.*"))}, tc.options...)
+			gqlsrv.Use(otelgqlgen.New(options...))
+			body := doRequestWithHeader(ctx, t, gqlsrv,
+				&graphql.RawParams{Query: `subscription {userUpdated(names: ["a", "invalid"]) {name}}`},
+				http.Header{"Accept": []string{"text/event-stream"}})
+			if got := strings.Count(string(body), "event: next"); got != 2 {
+				t.Errorf("events: want=2 got=%d\n%s", got, body)
+			}
+			if diff := cmp.Diff(tc.want, toSubscriptionSpans(exporter.GetSpans())); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+			if started, ended := counter.started.Load(), counter.ended.Load(); started != ended {
+				t.Errorf("spans: started=%d ended=%d", started, ended)
+			}
+		})
+	}
+}
+
+func toSubscriptionSpans(spans tracetest.SpanStubs) []subscriptionSpan {
+	names := map[string]string{}
+	for _, span := range spans {
+		names[span.SpanContext.SpanID().String()] = span.Name
+	}
+	ret := make([]subscriptionSpan, 0, len(spans))
+	for _, span := range spans {
+		s := subscriptionSpan{Name: span.Name, Parent: names[span.Parent.SpanID().String()], Status: span.Status.Code}
+		for _, link := range span.Links {
+			s.Linked = names[link.SpanContext.SpanID().String()]
+		}
+		for _, attr := range span.Attributes {
+			switch attr.Key {
+			case attribute.Key("graphql.subscription.event.sequence"):
+				s.Sequence = attr.Value.AsInt64()
+			case attribute.Key("graphql.subscription.events"):
+				s.Events = attr.Value.AsInt64()
+			}
+		}
+		ret = append(ret, s)
+	}
+	return ret
+}
+
+// slowSubscriptionRoot is the resolver root that emits the subscription events at intervals.
+type slowSubscriptionRoot struct {
+	*resolvers.Resolver
+	interval time.Duration
+}
+
+func (r slowSubscriptionRoot) Subscription() execschema.SubscriptionResolver { return r }
+
+func (r slowSubscriptionRoot) UserUpdated(ctx context.Context, names []string) (<-chan *model.User, error) {
+	ch := make(chan *model.User)
+	go func() {
+		defer close(ch)
+		for _, name := range names {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(r.interval):
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- &model.User{Name: name}:
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func TestTracer_subscriptionEventDuration(t *testing.T) {
+	const interval = 100 * time.Millisecond
+	ctx := testContext(t)
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	gqlsrv := handler.New(execschema.NewExecutableSchema(execschema.Config{Resolvers: slowSubscriptionRoot{Resolver: &resolvers.Resolver{}, interval: interval}}))
+	gqlsrv.AddTransport(transport.SSE{})
+	gqlsrv.Use(otelgqlgen.New(otelgqlgen.WithTracerProvider(tp), otelgqlgen.WithMeterProvider(mp), otelgqlgen.WithSubscriptionMode(otelgqlgen.SubscriptionChildEvents)))
+	_ = doRequestWithHeader(ctx, t, gqlsrv,
+		&graphql.RawParams{Query: `subscription {userUpdated(names: ["a", "b"]) {name}}`},
+		http.Header{"Accept": []string{"text/event-stream"}})
+	var events int
+	for _, span := range exporter.GetSpans() {
+		if span.Name != "subscription event" {
+			continue
+		}
+		events++
+		// the event span must not contain the wait for the event.
+		if d := span.EndTime.Sub(span.StartTime); d >= interval/2 {
+			t.Errorf("event span lasts %s; it must not contain the interval between the events", d)
+		}
+	}
+	if events != 2 {
+		t.Errorf("events: want=2 got=%d", events)
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			data, ok := m.Data.(metricdata.Histogram[float64])
+			if !ok || m.Name != "graphql.operation.duration" {
+				continue
+			}
+			for _, dp := range data.DataPoints {
+				if v, ok := dp.Max.Value(); ok && v >= (interval/2).Seconds() {
+					t.Errorf("operation duration: max=%fs; it must not contain the interval between the events", v)
+				}
+			}
+		}
+	}
+}
@@ -3,7 +3,9 @@ package otelgqlgen
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
@@ -12,6 +14,8 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -25,10 +29,39 @@ const (
 
 type config struct {
 	tracerProvider            trace.TracerProvider
-	errorSelector             ErrorSelector
+	meterProvider             metric.MeterProvider
+	propagator                propagation.TextMapPropagator
+	errorRecorder             ErrorRecorder
+	fieldFilter               FieldFilter
+	variableRedaction         RedactionPolicy
+	argumentRedaction         RedactionPolicy
+	redactionHashKey          []byte
 	complexityExtensionName   string
+	sensitiveDirective        string
 	traceStructFields         bool
+	typedAttributes           bool
+	recordArgumentValues      bool
+	stableFieldSpanNames      bool
+	aggregateListItems        bool
 	shouldTraceCaptureTimings bool
+	aggregateSampleItems      int
+	subscriptionMode          SubscriptionMode
+	traceIncrementalDelivery  bool
+	propagateFromExtensions   bool
+	maxFieldDepth             int
+	slowFieldThreshold        time.Duration
+	operationSpanKind         trace.SpanKind
+	nestedSpanKind            trace.SpanKind
+	spanNameFormatter         SpanNameFormatter
+	responseTraceExtension    *TraceExtension
+	traceExtensionOnError     bool
+	errorTraceExtension       *TraceExtension
+	apolloTracing             func(ctx context.Context) bool
+	exposeServerTiming        bool
+	errorExtensionKeys        []string
+	errorClassifier           ErrorClassifier
+	unknownErrorsAsServer     bool
+	parentSpanErrors          ParentSpanErrors
 }
 
 type Option func(c *config)
@@ -40,6 +73,36 @@ func WithTracerProvider(tp trace.TracerProvider) Option {
 	}
 }
 
+// WithMeterProvider creates an Option that tells Tracer to use given MeterProvider.
+//
+// The Tracer records the operation duration, the number of operations, the number of errors and the resolver duration.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPropagators creates an Option that tells Tracer to use the given propagator to extract the trace context.
+//
+// See also ExtractTraceContextFromExtensions.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = p
+	}
+}
+
+// ExtractTraceContextFromExtensions creates an Option that tells Tracer to extract the trace context from the request.
+//
+// default value: false
+// The true means Tracer extracts the trace context (e.g. traceparent and tracestate) from the string values of the request extensions,
+// or the websocket init payload if the extensions do not have it, and uses it as the remote parent of the operation span.
+// It is useful for the websocket transports that have no HTTP headers per operation.
+func ExtractTraceContextFromExtensions(v bool) Option {
+	return func(c *config) {
+		c.propagateFromExtensions = v
+	}
+}
+
 // WithComplexityLimitExtensionName creates an Option that tells Tracer to get complexity stats calculated by the extension identified by the given name.
 func WithComplexityLimitExtensionName(extName string) Option {
 	return func(c *config) {
@@ -57,6 +120,28 @@ func TraceStructFields(v bool) Option {
 	}
 }
 
+// WithFieldFilter creates an Option that tells Tracer to trace only the fields that the given filter returns true.
+//
+// The fields that the filter returns false are not traced but still recorded in the resolver duration metric.
+//
+// See also IncludeFields and ExcludeFields.
+func WithFieldFilter(fn FieldFilter) Option {
+	return func(c *config) { c.fieldFilter = fn }
+}
+
+// TypedAttributes creates an Option that tells Tracer to record the operation variables and the field arguments as the typed attributes.
+//
+// default value: false
+// The false means the Tracer records them as the strings.
+// The true means the Tracer records the numbers, the booleans and the lists of them as the attributes of the corresponding types,
+// and flattens the objects into the attributes per the keys.
+// The null and absent values are omitted instead of being recorded as the strings, so that the attributes of the same key always have the same type.
+func TypedAttributes(v bool) Option {
+	return func(c *config) {
+		c.typedAttributes = v
+	}
+}
+
 // ErrorSelector is a predicate that the error should be recorded.
 //
 // The span records only errors that the function returns true.
@@ -64,13 +149,221 @@ func TraceStructFields(v bool) Option {
 type ErrorSelector func(err error) bool
 
 // WithErrorSelector creates an Option that tells Tracer uses the given selector.
-func WithErrorSelector(fn ErrorSelector) Option { return func(c *config) { c.errorSelector = fn } }
+//
+// The nil selector means the default one that selects all of the errors.
+func WithErrorSelector(fn ErrorSelector) Option {
+	return func(c *config) {
+		if fn == nil {
+			// a nil ErrorSelector in the interface is not a nil ErrorRecorder.
+			c.errorRecorder = nil
+			return
+		}
+		c.errorRecorder = fn
+	}
+}
+
+// WithErrorExtensionKeys creates an Option that tells Tracer to record the values of the given keys in the error extensions as the attributes of the error events.
+//
+// The attributes are named like "graphql.errors.extensions.<key>".
+// The error code (the "code" key of the extensions) is always recorded as "graphql.errors.code" regardless of this option.
+func WithErrorExtensionKeys(keys ...string) Option {
+	return func(c *config) { c.errorExtensionKeys = keys }
+}
+
+// WithVariableRedaction creates an Option that tells Tracer to redact the operation variables according to the given policy.
+//
+// The policy is called with the variable name and the operation context.
+// By default, Tracer records all of the variables as is.
+func WithVariableRedaction(p RedactionPolicy) Option {
+	return func(c *config) { c.variableRedaction = p }
+}
+
+// WithArgumentRedaction creates an Option that tells Tracer to redact the field arguments according to the given policy.
+//
+// The policy is called with the argument name and the context that holds the field context.
+// By default, Tracer records all of the arguments as is.
+func WithArgumentRedaction(p RedactionPolicy) Option {
+	return func(c *config) { c.argumentRedaction = p }
+}
+
+// WithRedactionHashKey creates an Option that tells Tracer to compute the digests of RedactionHash with HMAC-SHA256 keyed by the given key.
+//
+// default value: nil; RedactionHash computes the plain SHA-256 digests that can be reversed by the dictionary attacks.
+// The key should be a secret that is not recorded anywhere else.
+func WithRedactionHashKey(key []byte) Option {
+	return func(c *config) { c.redactionHashKey = key }
+}
+
+// RecordArgumentValues creates an Option that tells Tracer to record the argument values that the resolver received.
+//
+// default value: false
+// The false means the Tracer records the arguments as written in the query, so the variable references are recorded as is (e.g. "$name").
+// The true means the Tracer records the coerced values from the field context instead.
+// The arguments that reference the variables are redacted at least as strictly as the variables by the policy of WithVariableRedaction.
+func RecordArgumentValues(v bool) Option {
+	return func(c *config) {
+		c.recordArgumentValues = v
+	}
+}
+
+// WithSensitiveDirective creates an Option that tells Tracer to mask the values passed to the arguments and the input fields annotated with the directive.
+//
+// The directive must be declared in the schema like below:
+//
+//	directive @sensitive on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+//
+// The values are masked in the field arguments and the operation variables, even if they are passed through the variables.
+// Tracer.Validate returns an error if the directive is not declared in the schema.
+func WithSensitiveDirective(name string) Option {
+	return func(c *config) { c.sensitiveDirective = name }
+}
 
 // ShouldTraceCaptureTimings creates an [Option] that tells the [Tracer] to trace GraphQL timings.
 func ShouldTraceCaptureTimings(v bool) Option {
 	return func(c *config) { c.shouldTraceCaptureTimings = v }
 }
 
+// StableFieldSpanNames creates an Option that tells Tracer to name the field spans without the list indexes.
+//
+// default value: false
+// The false means the field span names contain the list indexes (e.g. "User/0/name").
+// The true means the field span names consist of the object name and the field name (e.g. "User/name"),
+// and the list index is recorded as the graphql.resolver.index attribute instead.
+func StableFieldSpanNames(v bool) Option {
+	return func(c *config) {
+		c.stableFieldSpanNames = v
+	}
+}
+
+// AggregateListItemFields creates an Option that tells Tracer to aggregate the field spans under the list items.
+//
+// Tracer creates one span per the field path without the list indexes (e.g. "users.name") instead of the spans per the items.
+// The aggregated span has the number of the items, the number of the errors and the total, min, max and 99th percentile durations as the attributes.
+// The aggregated span is named by the field span name formatter (see WithSpanNameFormatter) with the field context without the list indexes (e.g. "User/name").
+//
+// The fields of the first sampleItems items are also traced as the regular spans.
+func AggregateListItemFields(sampleItems int) Option {
+	return func(c *config) {
+		c.aggregateListItems = true
+		c.aggregateSampleItems = sampleItems
+	}
+}
+
+// WithMaxFieldDepth creates an Option that tells Tracer not to trace the fields deeper than the given depth.
+//
+// The depth of the root fields is 1, and the list indexes are not counted (e.g. the depth of "users[0].name" is 2).
+// The field spans at the max depth have the number of the fields selected under them as the graphql.resolver.untraced_fields attribute.
+// The deeper fields are still recorded in the resolver duration metric.
+// The zero or negative depth means no limit.
+func WithMaxFieldDepth(depth int) Option {
+	return func(c *config) { c.maxFieldDepth = depth }
+}
+
+// WithSlowFieldThreshold creates an Option that tells Tracer to trace only the fields that took the given duration or longer, or failed.
+//
+// Tracer creates the field spans after the resolvers return, so the spans of the descendant fields are not parented by them.
+// The other fields are summarized as the graphql.operation.fast_fields.count and graphql.operation.fast_fields.duration.total attributes of the operation span.
+// The zero or negative threshold means all of the fields are traced.
+func WithSlowFieldThreshold(threshold time.Duration) Option {
+	return func(c *config) { c.slowFieldThreshold = threshold }
+}
+
+// WithSubscriptionMode creates an Option that tells Tracer to trace the subscription operations in the given mode.
+//
+// default value: SubscriptionPerEvent
+// The event spans have the sequence number of the event and the duration since the previous event as the attributes.
+func WithSubscriptionMode(mode SubscriptionMode) Option {
+	return func(c *config) { c.subscriptionMode = mode }
+}
+
+// TraceIncrementalDelivery creates an Option that tells Tracer to trace the operations delivered incrementally as one operation span.
+//
+// default value: false
+// The false means Tracer creates an operation span per the payload.
+// The true means Tracer creates the operation span that lasts until the last payload is delivered if the operation has the deferred fragments,
+// so the deferred fields are traced as the children of it.
+// Each payload is recorded as the graphql.payload event that has the label, the path and whether the more payloads follow.
+//
+// Note that gqlgen supports only @defer at present.
+func TraceIncrementalDelivery(v bool) Option {
+	return func(c *config) { c.traceIncrementalDelivery = v }
+}
+
+// WithResponseTraceExtension creates an Option that tells Tracer to add the trace information of the operation span to the response extensions.
+//
+// If onlyOnError is true, Tracer adds it only to the responses that have any error.
+func WithResponseTraceExtension(ext TraceExtension, onlyOnError bool) Option {
+	return func(c *config) {
+		ext = ext.withDefaults()
+		c.responseTraceExtension = &ext
+		c.traceExtensionOnError = onlyOnError
+	}
+}
+
+// WithErrorTraceExtension creates an Option that tells Tracer to add the trace information to the extensions of each error in the response.
+//
+// The span ID is the one of the nearest field span on the error path, or the operation span if no field span is found.
+func WithErrorTraceExtension(ext TraceExtension) Option {
+	return func(c *config) {
+		ext = ext.withDefaults()
+		c.errorTraceExtension = &ext
+	}
+}
+
+// WithApolloTracing creates an Option that tells Tracer to add the Apollo Tracing v1 extension to the response if the given predicate returns true.
+//
+// The predicate is called with the context that holds the operation context, so it can decide per request (e.g. by the headers in the operation context).
+// The subscriptions and the incremental deliveries are not supported.
+func WithApolloTracing(pred func(ctx context.Context) bool) Option {
+	return func(c *config) { c.apolloTracing = pred }
+}
+
+// ExposeServerTiming creates an Option that tells Tracer to record the timings of the parsing, the validation and the execution for Server-Timing response header.
+//
+// The header is written by ServerTimingMiddleware that wraps the GraphQL handler.
+//
+// default value: false
+func ExposeServerTiming(v bool) Option {
+	return func(c *config) { c.exposeServerTiming = v }
+}
+
+// WithOperationSpanKind creates an Option that tells Tracer to create the operation spans with the given kind.
+//
+// default value: trace.SpanKindServer
+func WithOperationSpanKind(kind trace.SpanKind) Option {
+	return func(c *config) { c.operationSpanKind = kind }
+}
+
+// WithNestedSpanKind creates an Option that tells Tracer to create the spans under the operation span with the given kind.
+//
+// default value: trace.SpanKindServer
+// The nested spans are the phase spans (read, parsing and validation) and the field spans.
+// trace.SpanKindInternal is recommended so that the backends count one GraphQL request as one server request.
+func WithNestedSpanKind(kind trace.SpanKind) Option {
+	return func(c *config) { c.nestedSpanKind = kind }
+}
+
+// SpanNameFormatter formats the names of the spans that Tracer creates.
+//
+// The nil functions fall back to the default formats.
+type SpanNameFormatter struct {
+	// Operation returns the name of the operation span.
+	//
+	// The default is the operation name, or the operation type if the operation is anonymous.
+	Operation func(opCtx *graphql.OperationContext) string
+
+	// Field returns the name of the field span.
+	//
+	// The default is the object name and the field name joined by the slashes with the list indexes (e.g. "User/0/name").
+	// See also StableFieldSpanNames.
+	Field func(fieldCtx *graphql.FieldContext) string
+}
+
+// WithSpanNameFormatter creates an Option that tells Tracer to name the spans with the given formatter.
+func WithSpanNameFormatter(f SpanNameFormatter) Option {
+	return func(c *config) { c.spanNameFormatter = f }
+}
+
 // New returns a new Tracer with given options.
 func New(opts ...Option) Tracer {
 	cfg := &config{
@@ -82,18 +375,80 @@ func New(opts ...Option) Tracer {
 	if cfg.tracerProvider == nil {
 		cfg.tracerProvider = otel.GetTracerProvider()
 	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+	if cfg.propagator == nil {
+		cfg.propagator = otel.GetTextMapPropagator()
+	}
 	t := Tracer{
 		tracer:                    cfg.tracerProvider.Tracer(tracerName),
+		instruments:               newInstruments(cfg.meterProvider.Meter(tracerName)),
+		propagator:                cfg.propagator,
 		complexityExtensionName:   cfg.complexityExtensionName,
 		traceStructFields:         cfg.traceStructFields,
-		errorSelector:             cfg.errorSelector,
+		typedAttributes:           cfg.typedAttributes,
+		recordArgumentValues:      cfg.recordArgumentValues,
+		stableFieldSpanNames:      cfg.stableFieldSpanNames,
+		aggregateListItems:        cfg.aggregateListItems,
+		aggregateSampleItems:      cfg.aggregateSampleItems,
+		maxFieldDepth:             cfg.maxFieldDepth,
+		subscriptionMode:          cfg.subscriptionMode,
+		traceIncrementalDelivery:  cfg.traceIncrementalDelivery,
+		propagateFromExtensions:   cfg.propagateFromExtensions,
+		slowFieldThreshold:        cfg.slowFieldThreshold,
+		operationSpanKind:         cfg.operationSpanKind,
+		nestedSpanKind:            cfg.nestedSpanKind,
+		errorRecorder:             cfg.errorRecorder,
+		fieldFilter:               cfg.fieldFilter,
+		variableRedaction:         cfg.variableRedaction,
+		argumentRedaction:         cfg.argumentRedaction,
+		redactionHashKey:          cfg.redactionHashKey,
 		shouldTraceCaptureTimings: cfg.shouldTraceCaptureTimings,
+		operationSpanName:         cfg.spanNameFormatter.Operation,
+		responseTraceExtension:    cfg.responseTraceExtension,
+		traceExtensionOnError:     cfg.traceExtensionOnError,
+		errorTraceExtension:       cfg.errorTraceExtension,
+		apolloTracing:             cfg.apolloTracing,
+		exposeServerTiming:        cfg.exposeServerTiming,
+		errorExtensionKeys:        cfg.errorExtensionKeys,
+		errorClassifier:           cfg.errorClassifier,
+		unknownErrorsAsServer:     cfg.unknownErrorsAsServer,
+		parentSpanErrors:          cfg.parentSpanErrors,
+		fieldSpanName:             cfg.spanNameFormatter.Field,
+	}
+	if cfg.sensitiveDirective != "" {
+		t.sensitive = newSensitiveSchema(cfg.sensitiveDirective)
+	}
+	if t.operationSpanName == nil {
+		t.operationSpanName = operationName
+	}
+	if t.fieldSpanName == nil {
+		t.fieldSpanName = fieldSpanName
+		if t.stableFieldSpanNames {
+			t.fieldSpanName = stableFieldSpanName
+		}
+	}
+	if t.operationSpanKind == trace.SpanKindUnspecified {
+		t.operationSpanKind = trace.SpanKindServer
+	}
+	if t.nestedSpanKind == trace.SpanKindUnspecified {
+		t.nestedSpanKind = trace.SpanKindServer
 	}
 	if t.complexityExtensionName == "" {
 		t.complexityExtensionName = defaultComplexityExtensionName
 	}
-	if t.errorSelector == nil {
-		t.errorSelector = func(_ error) bool { return true }
+	if t.errorRecorder == nil {
+		t.errorRecorder = ErrorSelector(func(_ error) bool { return true })
+	}
+	if t.fieldFilter == nil {
+		t.fieldFilter = func(_ *graphql.FieldContext) bool { return true }
+	}
+	if t.variableRedaction == nil {
+		t.variableRedaction = func(_ context.Context, _ string) Redaction { return RedactionNone }
+	}
+	if t.argumentRedaction == nil {
+		t.argumentRedaction = func(_ context.Context, _ string) Redaction { return RedactionNone }
 	}
 	return t
 }
@@ -101,14 +456,45 @@ func New(opts ...Option) Tracer {
 // Tracer is a gqlgen extension to collect traces from the resolver.
 type Tracer struct {
 	tracer                    trace.Tracer
-	errorSelector             ErrorSelector
+	instruments               instruments
+	propagator                propagation.TextMapPropagator
+	errorRecorder             ErrorRecorder
+	fieldFilter               FieldFilter
+	variableRedaction         RedactionPolicy
+	argumentRedaction         RedactionPolicy
+	redactionHashKey          []byte
+	sensitive                 *sensitiveSchema
 	complexityExtensionName   string
 	traceStructFields         bool
+	typedAttributes           bool
+	recordArgumentValues      bool
+	stableFieldSpanNames      bool
+	aggregateListItems        bool
 	shouldTraceCaptureTimings bool
+	aggregateSampleItems      int
+	subscriptionMode          SubscriptionMode
+	traceIncrementalDelivery  bool
+	propagateFromExtensions   bool
+	maxFieldDepth             int
+	slowFieldThreshold        time.Duration
+	operationSpanKind         trace.SpanKind
+	nestedSpanKind            trace.SpanKind
+	operationSpanName         func(opCtx *graphql.OperationContext) string
+	fieldSpanName             func(fieldCtx *graphql.FieldContext) string
+	responseTraceExtension    *TraceExtension
+	traceExtensionOnError     bool
+	errorTraceExtension       *TraceExtension
+	apolloTracing             func(ctx context.Context) bool
+	exposeServerTiming        bool
+	errorExtensionKeys        []string
+	errorClassifier           ErrorClassifier
+	unknownErrorsAsServer     bool
+	parentSpanErrors          ParentSpanErrors
 }
 
 var _ interface {
 	graphql.HandlerExtension
+	graphql.OperationInterceptor
 	graphql.ResponseInterceptor
 	graphql.FieldInterceptor
 } = Tracer{}
@@ -117,43 +503,94 @@ func (Tracer) ExtensionName() string {
 	return extensionName
 }
 
-func (Tracer) Validate(_ graphql.ExecutableSchema) error {
-	return nil
+func (t Tracer) Validate(schema graphql.ExecutableSchema) error {
+	return t.sensitive.collect(schema.Schema())
 }
 
 func (t Tracer) startResponseSpan(ctx context.Context) (context.Context, trace.Span) {
+	ctx = t.extractTraceContext(ctx)
 	opCtx := graphql.GetOperationContext(ctx)
-	name := operationName(ctx)
 	opts := make([]trace.SpanStartOption, 0, 3)
 	attrs := make([]attribute.KeyValue, 0, 2)
-	attrs = append(attrs, semconv.GraphqlOperationNameKey.String(name))
+	attrs = append(attrs, semconv.GraphqlOperationNameKey.String(operationName(opCtx)))
 	if op := opCtx.Operation; op != nil {
 		attrs = append(attrs, semconv.GraphqlOperationTypeKey.String(string(op.Operation)))
 	}
 	opts = append(opts,
-		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithSpanKind(t.operationSpanKind),
 		trace.WithAttributes(attrs...))
 	if !opCtx.Stats.OperationStart.IsZero() {
 		opts = append(opts, trace.WithTimestamp(opCtx.Stats.OperationStart))
 	}
-	return t.tracer.Start(ctx, name, opts...)
+	return t.tracer.Start(ctx, t.operationSpanName(opCtx), opts...)
 }
 
-func (t Tracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+func (t Tracer) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	op := graphql.GetOperationContext(ctx).Operation
+	if op == nil {
+		return next(ctx)
+	}
+	if t.subscriptionMode != SubscriptionPerEvent && op.Operation == ast.Subscription {
+		return t.interceptSubscription(ctx, next)
+	}
+	if t.traceIncrementalDelivery && hasDeferredFragments(op.SelectionSet) {
+		return t.interceptIncrementalDelivery(ctx, next)
+	}
+	return next(ctx)
+}
+
+func (t Tracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) (resp *graphql.Response) {
+	if sub := subscriptionFromContext(ctx); sub != nil {
+		return t.interceptSubscriptionEvent(ctx, sub, next)
+	}
+	if inc := incrementalDeliveryFromContext(ctx); inc != nil {
+		return next(ctx)
+	}
+	ctx, at := t.withApolloTracing(ctx)
+	defer at.end()
+	defer t.recordServerTiming(ctx, time.Now())
 	parentSpan := trace.SpanFromContext(ctx)
+	opCtx := graphql.GetOperationContext(ctx)
+	startedAt := opCtx.Stats.OperationStart
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+	// the response handler is called per the event of the subscription, so each event is measured from its arrival instead of the start of the operation.
+	var arrival *eventArrival
+	if opCtx.Operation != nil && opCtx.Operation.Operation == ast.Subscription {
+		arrival = &eventArrival{}
+		ctx = withEventArrival(ctx, arrival)
+	}
 	ctx, span := t.startResponseSpan(ctx)
 	defer span.End()
+	defer func() {
+		if arrival != nil {
+			startedAt = arrival.arrive()
+		}
+		t.recordOperationMetrics(ctx, startedAt, resp)
+	}()
+	defer func() { t.setResponseTraceExtension(resp, span.SpanContext()) }()
 	if !span.IsRecording() {
 		return next(ctx)
 	}
 	if t.shouldTraceCaptureTimings {
 		t.captureOperationTimings(ctx)
 	}
+	span.SetAttributes(t.attrsOperation(ctx)...)
+	resp = t.traceResponse(ctx, parentSpan, span, next)
+	if resp != nil {
+		t.recordParentSpanErrors(parentSpan, resp.Errors)
+	}
+	return resp
+}
 
+func (t Tracer) attrsOperation(ctx context.Context) []attribute.KeyValue {
 	opCtx := graphql.GetOperationContext(ctx)
 	attrs := make([]attribute.KeyValue, 0, len(opCtx.Variables)+2+2)
-	for k, v := range opCtx.Variables {
-		attrs = append(attrs, attrReqVariable(k, v))
+	for k, v := range t.sensitive.redactVariables(opCtx) {
+		if redacted, ok := t.variableRedaction(ctx, k).apply(v, t.redactionHashKey); ok {
+			attrs = append(attrs, t.attrsReqVariable(k, redacted)...)
+		}
 	}
 	if stats := extension.GetApqStats(ctx); stats != nil {
 		attrs = append(attrs,
@@ -167,17 +604,50 @@ func (t Tracer) InterceptResponse(ctx context.Context, next graphql.ResponseHand
 			keyComplexityCalculated.Int(stats.Complexity),
 		)
 	}
-	span.SetAttributes(attrs...)
+	return attrs
+}
+
+// traceResponse calls next and records the errors in the response on the span.
+func (t Tracer) traceResponse(ctx context.Context, parentSpan, span trace.Span, next graphql.ResponseHandler) *graphql.Response {
+	ctx, finish := t.withResponseState(ctx)
+	defer finish(span)
 	resp := next(ctx)
 	if resp != nil && len(resp.Errors) > 0 {
-		recordGQLErrors(span, resp.Errors, t.errorSelector)
-		if parentSpan.SpanContext().IsValid() {
-			recordGQLErrors(parentSpan, resp.Errors, t.errorSelector)
+		if t.recordsOperationSpanErrors(parentSpan) {
+			t.recordGQLErrors(span, resp.Errors)
 		}
+		t.setErrorTraceExtensions(ctx, resp.Errors, span.SpanContext())
 	}
 	return resp
 }
 
+// withResponseState returns the context that holds the states collected while the fields are resolved.
+//
+// The returned function records the collected states on the span and must be called after the response is built.
+func (t Tracer) withResponseState(ctx context.Context) (context.Context, func(span trace.Span)) {
+	var (
+		aggs *listItemAggregates
+		fast *fastFields
+	)
+	if t.aggregateListItems {
+		ctx, aggs = withListItemAggregates(ctx)
+	}
+	if t.slowFieldThreshold > 0 {
+		ctx, fast = withFastFields(ctx)
+	}
+	if t.errorTraceExtension != nil {
+		ctx, _ = withFieldSpans(ctx)
+	}
+	return ctx, func(span trace.Span) {
+		if aggs != nil {
+			aggs.end(ctx, t)
+		}
+		if fast != nil {
+			span.SetAttributes(fast.attributes()...)
+		}
+	}
+}
+
 func (t Tracer) captureOperationTimings(ctx context.Context) {
 	stats := graphql.GetOperationContext(ctx).Stats
 	var (
@@ -185,13 +655,13 @@ func (t Tracer) captureOperationTimings(ctx context.Context) {
 		span   trace.Span
 	)
 	timing = stats.Parsing
-	_, span = t.tracer.Start(ctx, "parsing", trace.WithTimestamp(timing.Start), trace.WithSpanKind(trace.SpanKindServer))
+	_, span = t.tracer.Start(ctx, "parsing", trace.WithTimestamp(timing.Start), trace.WithSpanKind(t.nestedSpanKind))
 	span.End(trace.WithTimestamp(timing.End))
 	timing = stats.Read
-	_, span = t.tracer.Start(ctx, "read", trace.WithTimestamp(timing.Start), trace.WithSpanKind(trace.SpanKindServer))
+	_, span = t.tracer.Start(ctx, "read", trace.WithTimestamp(timing.Start), trace.WithSpanKind(t.nestedSpanKind))
 	span.End(trace.WithTimestamp(timing.End))
 	timing = stats.Validation
-	_, span = t.tracer.Start(ctx, "validation", trace.WithTimestamp(timing.Start), trace.WithSpanKind(trace.SpanKindServer))
+	_, span = t.tracer.Start(ctx, "validation", trace.WithTimestamp(timing.Start), trace.WithSpanKind(t.nestedSpanKind))
 	span.End(trace.WithTimestamp(timing.End))
 }
 
@@ -208,35 +678,93 @@ func fieldSpanName(fc *graphql.FieldContext) string {
 	return w.String()
 }
 
+func stableFieldSpanName(fc *graphql.FieldContext) string {
+	return fc.Field.ObjectDefinition.Name + "/" + fc.Field.Name
+}
+
+func fieldIndex(fc *graphql.FieldContext) *int {
+	if fc.Parent != nil && fc.Parent.Index != nil {
+		return fc.Parent.Index
+	}
+	return fc.Index
+}
+
 func (t Tracer) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
 	fieldCtx := graphql.GetFieldContext(ctx)
+	if arrival := eventArrivalFromContext(ctx); arrival != nil {
+		arrival.arrive()
+	}
+	if at := apolloTracingFromContext(ctx); at != nil {
+		next = at.wrap(fieldCtx, next)
+	}
 	if !t.traceStructFields && (!fieldCtx.IsMethod && !fieldCtx.IsResolver) {
 		return next(ctx)
 	}
-	field := fieldCtx.Field
-	ctx, span := t.tracer.Start(ctx, fieldSpanName(fieldCtx), trace.WithSpanKind(trace.SpanKindServer))
+	// the fields that are not traced are still measured by the resolver duration metric.
+	if !t.fieldFilter(fieldCtx) {
+		defer t.recordResolverMetrics(ctx, fieldCtx, time.Now())
+		return next(ctx)
+	}
+	depth := fieldDepth(fieldCtx)
+	if t.maxFieldDepth > 0 && depth > t.maxFieldDepth {
+		defer t.recordResolverMetrics(ctx, fieldCtx, time.Now())
+		return next(ctx)
+	}
+	if ev := subscriptionEventFromContext(ctx); ev != nil {
+		ctx, _ = ev.start(ctx, t)
+	}
+	var aggs *listItemAggregates
+	if t.aggregateListItems && isListItemField(fieldCtx) {
+		aggs = listItemAggregatesFromContext(ctx)
+	}
+	if aggs != nil && !t.isSampledListItem(fieldCtx) {
+		return t.interceptAggregatedField(ctx, aggs, next)
+	}
+	if aggs != nil {
+		next = aggs.wrap(trace.SpanContextFromContext(ctx), fieldCtx, next)
+	}
+	if t.slowFieldThreshold > 0 {
+		return t.interceptSlowField(ctx, fieldCtx, depth, next)
+	}
+	ctx, span := t.tracer.Start(ctx, t.fieldSpanName(fieldCtx), trace.WithSpanKind(t.nestedSpanKind))
 	defer span.End()
+	if t.errorTraceExtension != nil {
+		fieldSpansFromContext(ctx).add(fieldCtx.Path().String(), span.SpanContext())
+	}
+	startedAt := time.Now()
+	defer t.recordResolverMetrics(ctx, fieldCtx, startedAt)
 	if !span.IsRecording() {
 		return next(ctx)
 	}
 
-	attrs := attrsField(field)
+	span.SetAttributes(t.attrsFieldSpan(ctx, fieldCtx, depth)...)
+
+	resp, err := next(ctx)
+	if errs := graphql.GetFieldErrors(ctx, fieldCtx); len(errs) > 0 {
+		t.recordGQLErrors(span, errs)
+	}
+	return resp, err
+}
+
+func (t Tracer) attrsFieldSpan(ctx context.Context, fieldCtx *graphql.FieldContext, depth int) []attribute.KeyValue {
+	attrs := t.attrsField(ctx, fieldCtx)
 	attrs = append(attrs,
 		keyResolverPath.String(fieldCtx.Path().String()),
 		keyFieldIsMethod.Bool(fieldCtx.IsMethod),
 		keyFieldIsResolver.Bool(fieldCtx.IsResolver),
 	)
-	span.SetAttributes(attrs...)
-
-	resp, err := next(ctx)
-	if errs := graphql.GetFieldErrors(ctx, fieldCtx); len(errs) > 0 {
-		recordGQLErrors(span, errs, t.errorSelector)
+	if idx := fieldIndex(fieldCtx); t.stableFieldSpanNames && idx != nil {
+		attrs = append(attrs, keyResolverIndex.Int(*idx))
 	}
-	return resp, err
+	if t.maxFieldDepth > 0 && depth == t.maxFieldDepth {
+		if n := countSelectedFields(fieldCtx.Field.Selections); n > 0 {
+			attrs = append(attrs, keyResolverUntracedFields.Int(n))
+		}
+	}
+	return attrs
 }
 
-func operationName(ctx context.Context) string {
-	opCtx := graphql.GetOperationContext(ctx)
+func operationName(opCtx *graphql.OperationContext) string {
 	if name := opCtx.OperationName; name != "" {
 		return name
 	}
@@ -250,20 +778,72 @@ func operationName(ctx context.Context) string {
 	return string(op.Operation)
 }
 
-func recordGQLErrors(span trace.Span, errs gqlerror.List, selector ErrorSelector) {
-	var recorded bool
+// recordGQLErrors records the errors on the span according to the error recorder, and sets the distinct error codes of the recorded errors on the span.
+func (t Tracer) recordGQLErrors(span trace.Span, errs gqlerror.List) {
+	status := codes.Unset
+	var errorCodes []string
 	for _, gqlErr := range errs {
-		if !selector(gqlErr) {
+		decision := t.decideError(gqlErr)
+		if decision.Recording == ErrorIgnore {
 			continue
 		}
-		recorded = true
-		attrErrorPath := keyErrorPath.String(gqlErr.Path.String())
-		span.RecordError(unwrapErr(gqlErr), trace.WithStackTrace(true), trace.WithAttributes(attrErrorPath))
+		attrs := t.attrsError(gqlErr)
+		switch decision.Recording {
+		case ErrorRecordAsException:
+			attrs = append(attrs, decision.Attributes...)
+			leaves := unwrapErrs(gqlErr)
+			for i, leaf := range leaves {
+				leafAttrs := attrs
+				if len(leaves) > 1 {
+					leafAttrs = append(slices.Clip(attrs), keyErrorLeafIndex.Int(i), keyErrorLeafCount.Int(len(leaves)))
+				}
+				span.RecordError(leaf, trace.WithStackTrace(true), trace.WithAttributes(leafAttrs...))
+			}
+		case ErrorRecordAsEvent:
+			attrs = append(attrs, keyErrorMessage.String(gqlErr.Message))
+			attrs = append(attrs, decision.Attributes...)
+			span.AddEvent(errorEventName, trace.WithAttributes(attrs...))
+		case ErrorIgnore:
+		}
+		if code := errorCode(gqlErr); code != "" && !slices.Contains(errorCodes, code) {
+			errorCodes = append(errorCodes, code)
+		}
+		if decision.Status == codes.Error || (decision.Status == codes.Ok && status == codes.Unset) {
+			status = decision.Status
+		}
+	}
+	if len(errorCodes) > 0 {
+		span.SetAttributes(keyErrorCodes.StringSlice(errorCodes))
 	}
-	if !recorded {
-		return
+	switch status {
+	case codes.Error:
+		span.SetStatus(codes.Error, errs.Error())
+	case codes.Ok:
+		span.SetStatus(codes.Ok, "")
+	case codes.Unset:
 	}
-	span.SetStatus(codes.Error, errs.Error())
+}
+
+func (t Tracer) attrsError(gqlErr *gqlerror.Error) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, 2+len(t.errorExtensionKeys))
+	attrs = append(attrs, keyErrorPath.String(gqlErr.Path.String()))
+	if code := errorCode(gqlErr); code != "" {
+		attrs = append(attrs, keyErrorCode.String(code))
+	}
+	for _, key := range t.errorExtensionKeys {
+		val, ok := gqlErr.Extensions[key]
+		if !ok {
+			continue
+		}
+		attrs = append(attrs, flattenAttrs(errorExtensionsPrefix.With(key), normalizeValue(val), t.typedAttributes)...)
+	}
+	return attrs
+}
+
+// errorCode returns the code in the extensions of the error (e.g. "GRAPHQL_VALIDATION_FAILED").
+func errorCode(gqlErr *gqlerror.Error) string {
+	code, _ := gqlErr.Extensions["code"].(string)
+	return code
 }
 
 func unwrapErr(err error) error {
@@ -281,7 +861,29 @@ func unwrapErr(err error) error {
 	}
 }
 
-func attrsField(field graphql.CollectedField) []attribute.KeyValue {
+// unwrapErrs returns the leaf errors of the error tree.
+//
+// The multi-errors (e.g. built by errors.Join) are walked into each of the wrapped errors.
+func unwrapErrs(err error) []error {
+	underlying := unwrapErr(err)
+	multi, ok := underlying.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{underlying}
+	}
+	var leaves []error
+	for _, wrapped := range multi.Unwrap() {
+		if wrapped != nil {
+			leaves = append(leaves, unwrapErrs(wrapped)...)
+		}
+	}
+	if len(leaves) == 0 {
+		return []error{underlying}
+	}
+	return leaves
+}
+
+func (t Tracer) attrsField(ctx context.Context, fieldCtx *graphql.FieldContext) []attribute.KeyValue {
+	field := fieldCtx.Field
 	max := 3 + len(field.Definition.Arguments)*2 + len(field.Directives)*2
 	attrs := make([]attribute.KeyValue, 0, max)
 	attrs = append(attrs,
@@ -293,72 +895,150 @@ func attrsField(field graphql.CollectedField) []attribute.KeyValue {
 		ns := directivePrefix.With(directive.Name)
 		attrs = append(attrs, ns.With("location").asKey().String(string(directive.Location)))
 		for _, arg := range directive.Arguments {
-			current := ns.With("args", arg.Name)
-			if len(arg.Value.Children) > 0 {
-				attrs = append(attrs, childAttrs(arg.Value.Children, current)...)
-			} else {
-				attrs = append(attrs, current.asKey().String(arg.Value.String()))
-			}
+			attrs = append(attrs, t.valueAttrs(arg.Value, ns.With("args", arg.Name), "")...)
 		}
 	}
 	for _, def := range field.Definition.Arguments {
 		current := argsPrefix.With(def.Name)
-		if arg := field.Arguments.ForName(def.Name); arg != nil {
-			if arg.Value != nil && len(arg.Value.Children) > 0 {
-				attrs = append(attrs, childAttrs(arg.Value.Children, current)...)
-			} else {
-				attrs = append(attrs,
-					argsPrefix.With(arg.Name).asKey().String(arg.Value.String()),
-				)
+		arg := field.Arguments.ForName(def.Name)
+		val := def.DefaultValue
+		if arg != nil {
+			val = arg.Value
+		}
+		redaction := t.argumentRedaction(ctx, def.Name)
+		switch {
+		case t.sensitive.isArgument(field.ObjectDefinition.Name, field.Name, def.Name):
+			attrs = append(attrs, current.asKey().String(redactedMask))
+		case t.recordArgumentValues:
+			argVal, ok := fieldCtx.Args[def.Name]
+			if !ok {
+				continue
+			}
+			argVal = t.sensitive.maskValue(normalizeValue(argVal), def.Type.Name())
+			// the coerced value contains the values of the variables, so redact it at least as strictly as the variables.
+			for _, name := range referencedVariables(val) {
+				redaction = redaction.stricter(t.variableRedaction(ctx, name))
+			}
+			if redacted, ok := redaction.apply(argVal, t.redactionHashKey); ok {
+				attrs = append(attrs, flattenAttrs(current, redacted, t.typedAttributes)...)
 			}
-		} else {
-			if def.DefaultValue != nil && len(def.DefaultValue.Children) > 0 {
-				attrs = append(attrs, childAttrs(def.DefaultValue.Children, current)...)
-			} else {
-				attrs = append(attrs, current.asKey().String(def.DefaultValue.String()))
+		case redaction != RedactionNone:
+			if redacted, ok := redaction.apply(val.String(), t.redactionHashKey); ok {
+				attrs = append(attrs, current.asKey().String(fmt.Sprintf("%+v", redacted)))
 			}
+		default:
+			attrs = append(attrs, t.valueAttrs(val, current, def.Type.Name())...)
+		}
+		if arg == nil {
 			attrs = append(attrs, current.With("default").asKey().Bool(true))
 		}
 	}
 	return attrs
 }
 
-func childAttrs(children ast.ChildValueList, ns attrNameHierarchy) []attribute.KeyValue {
+// referencedVariables returns the names of the variables referenced in the value.
+func referencedVariables(val *ast.Value) []string {
+	if val == nil {
+		return nil
+	}
+	if val.Kind == ast.Variable {
+		return []string{val.Raw}
+	}
+	var names []string
+	for _, child := range val.Children {
+		names = append(names, referencedVariables(child.Value)...)
+	}
+	return names
+}
+
+func (t Tracer) valueAttrs(val *ast.Value, ns attrNameHierarchy, typeName string) []attribute.KeyValue {
+	if t.typedAttributes {
+		if val == nil || val.Kind == ast.NullValue {
+			return nil
+		}
+		if attr, ok := typedASTAttr(ns.asKey(), val); ok {
+			return []attribute.KeyValue{attr}
+		}
+	}
+	if val != nil && len(val.Children) > 0 {
+		return t.childAttrs(val, ns, typeName)
+	}
+	return []attribute.KeyValue{ns.asKey().String(val.String())}
+}
+
+func (t Tracer) childAttrs(val *ast.Value, ns attrNameHierarchy, typeName string) []attribute.KeyValue {
 	attrs := make([]attribute.KeyValue, 0)
-	for _, child := range children {
+	for _, child := range val.Children {
 		current := ns.With(child.Name)
-		if len(child.Value.Children) > 0 {
-			attrs = append(attrs, childAttrs(child.Value.Children, current)...)
-		} else {
-			attrs = append(attrs, current.asKey().String(child.Value.String()))
+		if val.Kind != ast.ObjectValue {
+			attrs = append(attrs, t.valueAttrs(child.Value, current, typeName)...)
+			continue
+		}
+		if t.sensitive.isInputField(typeName, child.Name) {
+			attrs = append(attrs, current.asKey().String(redactedMask))
+			continue
 		}
+		attrs = append(attrs, t.valueAttrs(child.Value, current, t.sensitive.inputFieldType(typeName, child.Name))...)
 	}
 	return attrs
 }
 
-func attrReqVariable(key string, val any) attribute.KeyValue {
-	return reqVarsPrefix.With(key).asKey().String(fmt.Sprintf("%+v", val))
+func (t Tracer) attrsReqVariable(key string, val any) []attribute.KeyValue {
+	if t.typedAttributes {
+		return flattenAttrs(reqVarsPrefix.With(key), val, true)
+	}
+	return []attribute.KeyValue{reqVarsPrefix.With(key).asKey().String(fmt.Sprintf("%+v", val))}
 }
 
 var (
-	ns              = "graphql"
-	nsResolver      = ns + ".resolver"
-	nsReq           = ns + ".operation"
-	directivePrefix = attrNameHierarchy{nsResolver + ".directives"}
-	argsPrefix      = attrNameHierarchy{nsResolver + ".args"}
-	reqVarsPrefix   = attrNameHierarchy{nsReq + ".variables"}
-
-	keyAPQHash              = attribute.Key(nsReq + ".apq.hash")
-	keyAPQSendQuery         = attribute.Key(nsReq + ".apq.sent_query")
-	keyComplexityLimit      = attribute.Key(nsReq + ".complexity.limit")
-	keyComplexityCalculated = attribute.Key(nsReq + ".complexity.calculated")
-	keyResolverObject       = attribute.Key(nsResolver + ".object")
-	keyResolverFieldName    = attribute.Key(nsResolver + ".field")
-	keyResolverAlias        = attribute.Key(nsResolver + ".alias")
-	keyResolverPath         = attribute.Key(nsResolver + ".path")
-	keyFieldIsResolver      = attribute.Key(nsResolver + ".is_resolver")
-	keyFieldIsMethod        = attribute.Key(nsResolver + ".is_method")
-	keyErrorPath            = attribute.Key(ns + ".errors.path")
+	ns                    = "graphql"
+	nsResolver            = ns + ".resolver"
+	nsReq                 = ns + ".operation"
+	directivePrefix       = attrNameHierarchy{nsResolver + ".directives"}
+	argsPrefix            = attrNameHierarchy{nsResolver + ".args"}
+	reqVarsPrefix         = attrNameHierarchy{nsReq + ".variables"}
+	errorExtensionsPrefix = attrNameHierarchy{ns + ".errors.extensions"}
+
+	keyAPQHash                = attribute.Key(nsReq + ".apq.hash")
+	keyAPQSendQuery           = attribute.Key(nsReq + ".apq.sent_query")
+	keyComplexityLimit        = attribute.Key(nsReq + ".complexity.limit")
+	keyComplexityCalculated   = attribute.Key(nsReq + ".complexity.calculated")
+	keyResolverObject         = attribute.Key(nsResolver + ".object")
+	keyResolverFieldName      = attribute.Key(nsResolver + ".field")
+	keyResolverAlias          = attribute.Key(nsResolver + ".alias")
+	keyResolverPath           = attribute.Key(nsResolver + ".path")
+	keyResolverIndex          = attribute.Key(nsResolver + ".index")
+	keyResolverUntracedFields = attribute.Key(nsResolver + ".untraced_fields")
+	keyFieldIsResolver        = attribute.Key(nsResolver + ".is_resolver")
+	keyFieldIsMethod          = attribute.Key(nsResolver + ".is_method")
+	keyErrorPath              = attribute.Key(ns + ".errors.path")
+	keyErrorMessage           = attribute.Key(ns + ".errors.message")
+	keyErrorCode              = attribute.Key(ns + ".errors.code")
+	keyErrorCodes             = attribute.Key(ns + ".errors.codes")
+	keyErrorLeafIndex         = attribute.Key(ns + ".errors.leaf.index")
+	keyErrorLeafCount         = attribute.Key(ns + ".errors.leaf.count")
+	keyErrorKind              = attribute.Key(ns + ".error.kind")
+	keyErrorsCount            = attribute.Key(ns + ".errors.count")
+
+	keyFastFieldsCount         = attribute.Key(nsReq + ".fast_fields.count")
+	keyFastFieldsDurationTotal = attribute.Key(nsReq + ".fast_fields.duration.total")
+
+	keySubscriptionEvents        = attribute.Key(ns + ".subscription.events")
+	keySubscriptionEventSequence = attribute.Key(ns + ".subscription.event.sequence")
+	keySubscriptionEventInterval = attribute.Key(ns + ".subscription.event.interval")
+
+	keyPayloadSequence = attribute.Key(ns + ".payload.sequence")
+	keyPayloadLabel    = attribute.Key(ns + ".payload.label")
+	keyPayloadPath     = attribute.Key(ns + ".payload.path")
+	keyPayloadHasNext  = attribute.Key(ns + ".payload.has_next")
+	keyPayloadErrors   = attribute.Key(ns + ".payload.errors")
+
+	keyAggregateCount         = attribute.Key(nsResolver + ".aggregate.count")
+	keyAggregateErrors        = attribute.Key(nsResolver + ".aggregate.errors")
+	keyAggregateDurationTotal = attribute.Key(nsResolver + ".aggregate.duration.total")
+	keyAggregateDurationMin   = attribute.Key(nsResolver + ".aggregate.duration.min")
+	keyAggregateDurationMax   = attribute.Key(nsResolver + ".aggregate.duration.max")
+	keyAggregateDurationP99   = attribute.Key(nsResolver + ".aggregate.duration.p99")
 )
 
 type attrNameHierarchy []string
@@ -5,9 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/99designs/gqlgen/graphql"
@@ -141,6 +143,67 @@ func TestTracer(t *testing.T) {
 				{Name: "http_handler", SpanKind: trace.SpanKindInternal},
 			},
 		},
+		{
+			name: "ok/span name formatter",
+			options: []otelgqlgen.Option{
+				otelgqlgen.WithSpanNameFormatter(otelgqlgen.SpanNameFormatter{
+					Operation: func(opCtx *graphql.OperationContext) string {
+						return string(opCtx.Operation.Operation) + " " + opCtx.Operation.Name
+					},
+					Field: func(fc *graphql.FieldContext) string {
+						return fc.Field.ObjectDefinition.Name + "." + fc.Field.Name
+					},
+				}),
+			},
+			params: &graphql.RawParams{
+				Query:     `query namedOp($name: String!) {user(name: $name) @include(if: true) {name @include(if: true) isAdmin}}`,
+				Variables: map[string]any{"name": "aereal"},
+			},
+			spans: tracetest.SpanStubs{
+				{Name: "read", SpanKind: trace.SpanKindServer},
+				{Name: "parsing", SpanKind: trace.SpanKindServer},
+				{Name: "validation", SpanKind: trace.SpanKindServer},
+				{
+					Name:     "Query.user",
+					SpanKind: trace.SpanKindServer,
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.resolver.object", "Query"),
+						attribute.String("graphql.resolver.field", "user"),
+						attribute.String("graphql.resolver.alias", "user"),
+						attribute.String("graphql.resolver.directives.include.location", "FIELD"),
+						attribute.String("graphql.resolver.directives.include.args.if", "true"),
+						attribute.String("graphql.resolver.args.name", "$name"),
+						attribute.String("graphql.resolver.path", "user"),
+						attribute.Bool("graphql.resolver.is_method", true),
+						attribute.Bool("graphql.resolver.is_resolver", true),
+					}},
+				{
+					Name:     "User.name",
+					SpanKind: trace.SpanKindServer,
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.resolver.object", "User"),
+						attribute.String("graphql.resolver.field", "name"),
+						attribute.String("graphql.resolver.alias", "name"),
+						attribute.String("graphql.resolver.directives.include.location", "FIELD"),
+						attribute.String("graphql.resolver.directives.include.args.if", "true"),
+						attribute.String("graphql.resolver.path", "user.name"),
+						attribute.Bool("graphql.resolver.is_method", true),
+						attribute.Bool("graphql.resolver.is_resolver", true),
+					}},
+				{
+					Name:     "query namedOp",
+					SpanKind: trace.SpanKindServer,
+					Attributes: []attribute.KeyValue{
+						attribute.String("graphql.operation.name", "namedOp"),
+						attribute.String("graphql.operation.type", "query"),
+						attribute.String("graphql.operation.variables.name", "aereal"),
+						attribute.Int("graphql.operation.complexity.limit", 1000),
+						attribute.Int("graphql.operation.complexity.calculated", 3),
+					},
+				},
+				{Name: "http_handler", SpanKind: trace.SpanKindInternal},
+			},
+		},
 		{
 			name: "ok/name from parameter",
 			params: &graphql.RawParams{
@@ -703,12 +766,14 @@ func TestTracer_no_operation_provided(t *testing.T) {
 			SpanKind: trace.SpanKindServer,
 			Attributes: []attribute.KeyValue{
 				attribute.String("graphql.operation.name", "GraphQL Operation"),
+				attribute.StringSlice("graphql.errors.codes", []string{"GRAPHQL_VALIDATION_FAILED"}),
 			},
 			Events: []sdktrace.Event{
 				{
 					Name: semconv.ExceptionEventName,
 					Attributes: []attribute.KeyValue{
 						attribute.String("graphql.errors.path", ""),
+						attribute.String("graphql.errors.code", "GRAPHQL_VALIDATION_FAILED"),
 						semconv.ExceptionTypeKey.String("*gqlerror.Error"),
 						semconv.ExceptionMessageKey.String("input: no operation provided"),
 						attrStacktrace,
@@ -841,6 +906,255 @@ func TestTracer_no_timing_trace(t *testing.T) {
 	}
 }
 
+func TestTracer_recordArgumentValues(t *testing.T) {
+	type testCase struct {
+		name      string
+		params    *graphql.RawParams
+		options   []otelgqlgen.Option
+		fieldSpan string
+		want      []attribute.KeyValue
+	}
+	testCases := []testCase{
+		{
+			name: "variable",
+			params: &graphql.RawParams{
+				Query:     `query($name: String!) {user(name: $name) {name}}`,
+				Variables: map[string]any{"name": "aereal"},
+			},
+			fieldSpan: "Query/user",
+			want: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.name", "aereal"),
+			},
+		},
+		{
+			name: "redacted",
+			params: &graphql.RawParams{
+				Query:     `query($name: String!) {user(name: $name) {name}}`,
+				Variables: map[string]any{"name": "aereal"},
+			},
+			options:   []otelgqlgen.Option{otelgqlgen.WithArgumentRedaction(otelgqlgen.DenyNames(otelgqlgen.RedactionMask, "name"))},
+			fieldSpan: "Query/user",
+			want: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.name", "[REDACTED]"),
+			},
+		},
+		{
+			name: "redacted variable",
+			params: &graphql.RawParams{
+				Query:     `mutation($pw: String!) {signIn(name: "a", password: $pw)}`,
+				Variables: map[string]any{"pw": "hunter2"},
+			},
+			options:   []otelgqlgen.Option{otelgqlgen.WithVariableRedaction(otelgqlgen.DenyNames(otelgqlgen.RedactionMask, "pw"))},
+			fieldSpan: "Mutation/signIn",
+			want: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.name", "a"),
+				attribute.String("graphql.resolver.args.password", "[REDACTED]"),
+			},
+		},
+		{
+			name: "redacted variable in input object",
+			params: &graphql.RawParams{
+				Query:     `mutation($pw: String!) {signInWith(credentials: {name: "a", password: $pw})}`,
+				Variables: map[string]any{"pw": "hunter2"},
+			},
+			options:   []otelgqlgen.Option{otelgqlgen.WithVariableRedaction(otelgqlgen.DenyNames(otelgqlgen.RedactionDrop, "pw"))},
+			fieldSpan: "Mutation/signInWith",
+			want:      []attribute.KeyValue{},
+		},
+		{
+			name: "stricter argument redaction",
+			params: &graphql.RawParams{
+				Query:     `mutation($pw: String!) {signIn(name: "a", password: $pw)}`,
+				Variables: map[string]any{"pw": "hunter2"},
+			},
+			options: []otelgqlgen.Option{
+				otelgqlgen.WithVariableRedaction(otelgqlgen.DenyNames(otelgqlgen.RedactionMask, "pw")),
+				otelgqlgen.WithArgumentRedaction(otelgqlgen.DenyNames(otelgqlgen.RedactionDrop, "password")),
+			},
+			fieldSpan: "Mutation/signIn",
+			want: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.name", "a"),
+			},
+		},
+		{
+			name: "sensitive",
+			params: &graphql.RawParams{
+				Query:     `mutation($credentials: Credentials!) {signInWith(credentials: $credentials)}`,
+				Variables: map[string]any{"credentials": map[string]any{"name": "aereal", "password": "secret"}},
+			},
+			options:   []otelgqlgen.Option{otelgqlgen.WithSensitiveDirective("sensitive")},
+			fieldSpan: "Mutation/signInWith",
+			want: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.credentials.name", "aereal"),
+				attribute.String("graphql.resolver.args.credentials.password", "[REDACTED]"),
+			},
+		},
+		{
+			name: "default value",
+			params: &graphql.RawParams{
+				Query: `{root(num: 1)}`,
+			},
+			fieldSpan: "Query/root",
+			want: []attribute.KeyValue{
+				attribute.String("graphql.resolver.args.num", "1"),
+				attribute.Bool("graphql.resolver.args.rootInput.default", true),
+			},
+		},
+		{
+			name: "typed",
+			params: &graphql.RawParams{
+				Query:     `query($limit: Int!, $tags: [String!]) {search(limit: $limit, ratio: 0.5, tags: $tags)}`,
+				Variables: map[string]any{"limit": 100, "tags": []string{"a", "b"}},
+			},
+			options:   []otelgqlgen.Option{otelgqlgen.TypedAttributes(true)},
+			fieldSpan: "Query/search",
+			want: []attribute.KeyValue{
+				attribute.Int64("graphql.resolver.args.limit", 100),
+				attribute.Float64("graphql.resolver.args.ratio", 0.5),
+				attribute.Bool("graphql.resolver.args.exact.default", true),
+				attribute.StringSlice("graphql.resolver.args.tags", []string{"a", "b"}),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			options := append([]otelgqlgen.Option{otelgqlgen.RecordArgumentValues(true)}, tc.options...)
+			gqlsrv, exporter := newTestServer(t, options...)
+			_ = doRequest(ctx, t, gqlsrv, tc.params)
+			got := attrsWithPrefix(findSpan(t, exporter.GetSpans(), tc.fieldSpan).Attributes, "graphql.resolver.args.")
+			if diff := cmp.Diff(tc.want, got, cmp.Transformer("attribute.KeyValue", transformKeyValue), sortAttrs); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTracer_listFieldSpanNames(t *testing.T) {
+	type testCase struct {
+		name    string
+		options []otelgqlgen.Option
+		want    tracetest.SpanStubs
+	}
+	testCases := []testCase{
+		{
+			name: "default",
+			want: tracetest.SpanStubs{
+				{Name: "Query/users", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users")}},
+				{Name: "User/0/name", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users[0].name")}},
+				{Name: "User/1/name", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users[1].name")}},
+			},
+		},
+		{
+			name:    "stable",
+			options: []otelgqlgen.Option{otelgqlgen.StableFieldSpanNames(true)},
+			want: tracetest.SpanStubs{
+				{Name: "Query/users", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users")}},
+				{Name: "User/name", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users[0].name"), attribute.Int("graphql.resolver.index", 0)}},
+				{Name: "User/name", Attributes: []attribute.KeyValue{attribute.String("graphql.resolver.path", "users[1].name"), attribute.Int("graphql.resolver.index", 1)}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			options := append([]otelgqlgen.Option{otelgqlgen.ShouldTraceCaptureTimings(false)}, tc.options...)
+			gqlsrv, exporter := newTestServer(t, options...)
+			_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{Query: `{users(names: ["a", "b"]) {name}}`})
+			got := make(tracetest.SpanStubs, 0)
+			for _, span := range exporter.GetSpans() {
+				attrs := make([]attribute.KeyValue, 0, 2)
+				for _, attr := range span.Attributes {
+					if attr.Key == "graphql.resolver.path" || attr.Key == "graphql.resolver.index" {
+						attrs = append(attrs, attr)
+					}
+				}
+				if len(attrs) == 0 {
+					continue
+				}
+				got = append(got, tracetest.SpanStub{Name: span.Name, Attributes: attrs})
+			}
+			if diff := cmpFieldSpans(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTracer_spanKinds(t *testing.T) {
+	type testCase struct {
+		name    string
+		options []otelgqlgen.Option
+		want    map[string]trace.SpanKind
+	}
+	testCases := []testCase{
+		{
+			name: "default",
+			want: map[string]trace.SpanKind{
+				"query":      trace.SpanKindServer,
+				"read":       trace.SpanKindServer,
+				"parsing":    trace.SpanKindServer,
+				"validation": trace.SpanKindServer,
+				"Query/user": trace.SpanKindServer,
+				"User/name":  trace.SpanKindServer,
+			},
+		},
+		{
+			name:    "nested spans are internal",
+			options: []otelgqlgen.Option{otelgqlgen.WithNestedSpanKind(trace.SpanKindInternal)},
+			want: map[string]trace.SpanKind{
+				"query":      trace.SpanKindServer,
+				"read":       trace.SpanKindInternal,
+				"parsing":    trace.SpanKindInternal,
+				"validation": trace.SpanKindInternal,
+				"Query/user": trace.SpanKindInternal,
+				"User/name":  trace.SpanKindInternal,
+			},
+		},
+		{
+			name:    "operation span is internal",
+			options: []otelgqlgen.Option{otelgqlgen.WithOperationSpanKind(trace.SpanKindInternal)},
+			want: map[string]trace.SpanKind{
+				"query":      trace.SpanKindInternal,
+				"read":       trace.SpanKindServer,
+				"parsing":    trace.SpanKindServer,
+				"validation": trace.SpanKindServer,
+				"Query/user": trace.SpanKindServer,
+				"User/name":  trace.SpanKindServer,
+			},
+		},
+		{
+			name: "both",
+			options: []otelgqlgen.Option{
+				otelgqlgen.WithOperationSpanKind(trace.SpanKindInternal),
+				otelgqlgen.WithNestedSpanKind(trace.SpanKindInternal),
+			},
+			want: map[string]trace.SpanKind{
+				"query":      trace.SpanKindInternal,
+				"read":       trace.SpanKindInternal,
+				"parsing":    trace.SpanKindInternal,
+				"validation": trace.SpanKindInternal,
+				"Query/user": trace.SpanKindInternal,
+				"User/name":  trace.SpanKindInternal,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := testContext(t)
+			gqlsrv, exporter := newTestServer(t, tc.options...)
+			_ = doRequest(ctx, t, gqlsrv, &graphql.RawParams{Query: `{user(name: "aereal") {name}}`})
+			got := map[string]trace.SpanKind{}
+			for _, span := range exporter.GetSpans() {
+				got[span.Name] = span.SpanKind
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
 func cmpSpans(want, got tracetest.SpanStubs) string {
 	opts := []cmp.Option{
 		cmp.Transformer("attribute.KeyValue", transformKeyValue),
@@ -866,3 +1180,111 @@ var _ graphql.Cache[string] = (*noCache)(nil)
 func (noCache) Get(_ context.Context, _ string) (string, bool) { return "", false }
 
 func (noCache) Add(_ context.Context, _ string, _ string) {}
+
+// testContext returns the context that is canceled when the test ends or its deadline exceeds.
+func testContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx := t.Context()
+	if deadline, ok := t.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		t.Cleanup(cancel)
+	}
+	return ctx
+}
+
+// newTestServer returns the GraphQL server of the test schema traced by Tracer with the given options, and the exporter of the spans.
+//
+// The server accepts the POST requests. The other transports and the error presenter can be added to it.
+func newTestServer(t *testing.T, opts ...otelgqlgen.Option) (*handler.Server, *tracetest.InMemoryExporter) {
+	t.Helper()
+	return newTestServerWithTransport(t, transport.POST{}, opts...)
+}
+
+// newTestServerWithTransport is like newTestServer but the server accepts the requests through the given transport instead of POST.
+//
+// It is needed for the transports that accept the POST requests too (e.g. SSE), because the server uses the first transport that supports the request.
+func newTestServerWithTransport(t *testing.T, tr graphql.Transport, opts ...otelgqlgen.Option) (*handler.Server, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	gqlsrv := handler.New(execschema.NewExecutableSchema(execschema.Config{Resolvers: &resolvers.Resolver{}}))
+	gqlsrv.AddTransport(tr)
+	gqlsrv.Use(otelgqlgen.New(append([]otelgqlgen.Option{otelgqlgen.WithTracerProvider(tp)}, opts...)...))
+	return gqlsrv, exporter
+}
+
+func doRequest(ctx context.Context, t *testing.T, h http.Handler, params *graphql.RawParams) []byte {
+	t.Helper()
+	return doRequestWithHeader(ctx, t, h, params, nil)
+}
+
+func doRequestWithHeader(ctx context.Context, t *testing.T, h http.Handler, params *graphql.RawParams, header http.Header) []byte {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	// the transport decodes the body into the params including the headers, so they must not be sent as null
+	p := *params
+	p.Headers = header
+	body, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("http.NewRequestWithContext: %+v", err)
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("content-type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("http.Client.Do: %+v", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("io.ReadAll: %+v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("http.Response.Status: %d %#v %s", resp.StatusCode, resp.Header, string(respBody))
+	}
+	return respBody
+}
+
+var sortAttrs = cmpopts.SortSlices(func(x, y attribute.KeyValue) bool { return x.Key < y.Key })
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("span %q not found", name)
+	return tracetest.SpanStub{}
+}
+
+func attrsWithPrefix(attrs []attribute.KeyValue, prefix string) []attribute.KeyValue {
+	ret := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		if strings.HasPrefix(string(attr.Key), prefix) {
+			ret = append(ret, attr)
+		}
+	}
+	return ret
+}
+
+// cmpFieldSpans compares the spans regardless of the order since the list items are resolved concurrently.
+func cmpFieldSpans(want, got tracetest.SpanStubs) string {
+	opts := []cmp.Option{
+		cmp.Transformer("attribute.KeyValue", transformKeyValue),
+		sortAttrs,
+		cmpopts.SortSlices(func(x, y tracetest.SpanStub) bool { return fmt.Sprint(x.Attributes) < fmt.Sprint(y.Attributes) }),
+		cmpopts.IgnoreFields(tracetest.SpanStub{}, "Parent", "SpanContext", "SpanKind", "StartTime", "EndTime", "Links", "DroppedAttributes", "DroppedEvents", "DroppedLinks", "ChildSpanCount", "Resource", "InstrumentationLibrary", "InstrumentationScope"),
+	}
+	return cmp.Diff(want, got, opts...)
+}